@@ -0,0 +1,45 @@
+package idg
+
+// encodeBase will encode src as a fixed-width big-endian number of base len(alphabet)
+// Note: dst must be large enough to represent the largest value of src
+func encodeBase(dst, src []byte, alphabet string) {
+	var buf [32]byte
+	// Copy source into a scratch buffer, it is consumed by the division below
+	num := buf[:copy(buf[:], src)]
+	base := uint(len(alphabet))
+	for i := len(dst) - 1; i >= 0; i-- {
+		var rem uint
+		// Divide num by base, the remainder is the current digit
+		for j := range num {
+			acc := rem<<8 | uint(num[j])
+			num[j] = byte(acc / base)
+			rem = acc % base
+		}
+
+		dst[i] = alphabet[rem]
+	}
+}
+
+// decodeBase will decode the provided digit values as a big-endian number of the
+// provided base into dst. False is returned if the value does not fit within dst
+func decodeBase(dst, digits []byte, base uint) (ok bool) {
+	for i := range dst {
+		dst[i] = 0
+	}
+
+	for _, d := range digits {
+		carry := uint(d)
+		// Multiply dst by base and add the current digit
+		for j := len(dst) - 1; j >= 0; j-- {
+			acc := uint(dst[j])*base + carry
+			dst[j] = byte(acc)
+			carry = acc >> 8
+		}
+
+		if carry != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"github.com/PathDNA/idg"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrUnknownCodec is returned when an unknown codec name is provided
+	ErrUnknownCodec = errors.Error("unknown codec")
+	// ErrUnsupportedCodec is returned when a codec does not support an ID type
+	ErrUnsupportedCodec = errors.Error("codec is not supported for this ID type")
+)
+
+const (
+	codecUUID  = "uuid"
+	codecHuman = "human"
+	codecInt   = "int"
+)
+
+// Names of the supported codecs
+const codecList = "base64url, base62, base58, base32, hex, uuid, human"
+
+// getEncoding will return the built-in encoding with the provided name
+func getEncoding(name string) (enc idg.Encoding, ok bool) {
+	for _, enc = range idg.Encodings {
+		if enc.Name() == name {
+			return enc, true
+		}
+	}
+
+	return nil, false
+}
+
+// isCodec will return whether or not the provided name is a supported codec
+func isCodec(name string) bool {
+	if name == codecUUID || name == codecHuman {
+		return true
+	}
+
+	_, ok := getEncoding(name)
+	return ok
+}
+
+// parsed is a parsed ID of any type
+type parsed struct {
+	// Type of the ID, "id", "id32" or "id64"
+	typ   string
+	codec string
+
+	id   idg.ID
+	id32 idg.ID32
+	id64 idg.ID64
+}
+
+// parse will parse an ID of any type, utilizing the provided codec when set
+// Note: Numeric input is parsed as a snowflake ID64 only when it is not a valid ID or ID32
+// (e.g. an all-digit hex ID), utilize the int codec to parse it as an ID64. Several encodings
+// share a length (base64url, base62 and base58), the encoding is detected by
+// idg.DetectEncoding and idg.ErrAmbiguousEncoding is returned when the codec must be provided
+func parse(in, codec string) (p parsed, err error) {
+	switch codec {
+	case "":
+	case codecInt:
+		return parseID64(in)
+	case codecUUID:
+		p.typ, p.codec = "id", codecUUID
+		p.id, err = idg.ParseUUID(in)
+		return
+	case codecHuman:
+		return parseHuman(in)
+
+	default:
+		enc, ok := getEncoding(codec)
+		if !ok {
+			err = ErrUnknownCodec
+			return
+		}
+
+		return parseWith(enc, in)
+	}
+
+	if p, err = parse(in, codecUUID); err == nil {
+		return
+	}
+
+	enc, derr := idg.DetectEncoding(in)
+	if derr == nil {
+		if p, err = parseWith(enc, in); err == nil || !isNumeric(in) {
+			return
+		}
+	}
+
+	if isNumeric(in) {
+		if p, err = parseID64(in); err == nil {
+			return
+		}
+	}
+
+	if p, err = parseHuman(in); err == nil || isHumanError(err, idg.ErrInvalidChecksum) {
+		return
+	}
+
+	if err = idg.ErrInvalidEncoding; derr == idg.ErrAmbiguousEncoding {
+		err = derr
+	}
+
+	return
+}
+
+// isHumanError will return whether or not err is a human-friendly parsing error of the provided type
+func isHumanError(err, target error) bool {
+	herr, ok := err.(*idg.HumanError)
+	return ok && herr.Err == target
+}
+
+// parseWith will parse an ID or ID32 utilizing the provided encoding
+func parseWith(enc idg.Encoding, in string) (p parsed, err error) {
+	p.codec = enc.Name()
+	if p.id, err = idg.ParseWith(enc, in); err == nil {
+		p.typ = "id"
+		return
+	}
+
+	if p.id32, err = idg.ParseWith32(enc, in); err == nil {
+		p.typ = "id32"
+		return
+	}
+
+	return
+}
+
+// parseHuman will parse a human-friendly ID or ID32
+func parseHuman(in string) (p parsed, err error) {
+	p.codec = codecHuman
+	// The length of the input determines the type, errors other than length errors
+	// (e.g. the position of a checksum error) are reported as-is
+	if p.id, err = idg.ParseHuman(in); !isHumanError(err, idg.ErrInvalidLength) {
+		p.typ = "id"
+		return
+	}
+
+	p.typ = "id32"
+	p.id32, err = idg.ParseHuman32(in)
+	return
+}
+
+// parseID64 will parse a numeric ID64
+func parseID64(in string) (p parsed, err error) {
+	p.typ, p.codec = "id64", codecInt
+	p.id64, err = idg.ParseID64(in)
+	return
+}
+
+// isNumeric will return whether or not the provided string only contains digits
+func isNumeric(in string) bool {
+	for _, c := range []byte(in) {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return len(in) > 0
+}
+
+// format will format the parsed ID utilizing the provided codec
+func (p *parsed) format(codec string) (out string, err error) {
+	switch p.typ {
+	case "id":
+		return formatID(&p.id, codec)
+	case "id32":
+		return formatID32(&p.id32, codec)
+
+	default:
+		err = ErrUnsupportedCodec
+		return
+	}
+}
+
+// formatID will format an ID utilizing the provided codec
+func formatID(id *idg.ID, codec string) (out string, err error) {
+	switch codec {
+	case codecUUID:
+		return id.UUIDString(), nil
+	case codecHuman:
+		return id.HumanString(), nil
+	}
+
+	enc, ok := getEncoding(codec)
+	if !ok {
+		err = ErrUnknownCodec
+		return
+	}
+
+	return id.Format(enc), nil
+}
+
+// formatID32 will format an ID32 utilizing the provided codec
+func formatID32(id *idg.ID32, codec string) (out string, err error) {
+	switch codec {
+	case codecUUID:
+		err = ErrUnsupportedCodec
+		return
+	case codecHuman:
+		return id.HumanString(), nil
+	}
+
+	enc, ok := getEncoding(codec)
+	if !ok {
+		err = ErrUnknownCodec
+		return
+	}
+
+	return id.Format(enc), nil
+}
@@ -0,0 +1,311 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/PathDNA/idg"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidLayout is returned when an unknown layout name is provided
+	ErrInvalidLayout = errors.Error("invalid layout, expected index-first or uuidv7")
+	// ErrInvalidWidth is returned when an unknown width is provided
+	ErrInvalidWidth = errors.Error("invalid width, expected 128 or 32")
+	// ErrInvalidTime is returned when a time cannot be parsed
+	ErrInvalidTime = errors.Error("invalid time, expected RFC 3339 or Unix seconds")
+	// ErrInvalidSnowflake is returned when an unknown snowflake preset is provided
+	ErrInvalidSnowflake = errors.Error("invalid snowflake, expected twitter, discord or sonyflake")
+	// ErrIndexOverflow is returned when an index does not fit within an ID32
+	ErrIndexOverflow = errors.Error("index does not fit within 32 bits")
+)
+
+// snowflakes are the snowflake presets by name
+var snowflakes = map[string]idg.Snowflake{
+	"twitter":   idg.TwitterSnowflake,
+	"discord":   idg.DiscordSnowflake,
+	"sonyflake": idg.Sonyflake,
+}
+
+// result is the JSON output of a single ID
+type result struct {
+	Input string `json:"input,omitempty"`
+	// Formatted ID (encode, gen and convert)
+	ID    string `json:"id,omitempty"`
+	Type  string `json:"type,omitempty"`
+	Codec string `json:"codec,omitempty"`
+	// Layout of the ID, "index-first", "uuidv7" or "snowflake"
+	Layout   string     `json:"layout,omitempty"`
+	Index    *uint64    `json:"index,omitempty"`
+	Time     *time.Time `json:"time,omitempty"`
+	Node     *uint64    `json:"node,omitempty"`
+	Sequence *uint64    `json:"sequence,omitempty"`
+	Hex      string     `json:"hex,omitempty"`
+	Error    string     `json:"error,omitempty"`
+}
+
+// describe will set the decoded fields of the parsed ID
+func (r *result) describe(p *parsed, sf *idg.Snowflake) {
+	var (
+		idx uint64
+		t   time.Time
+	)
+
+	r.Type = p.typ
+	switch p.typ {
+	case "id":
+		r.Layout = p.id.Layout().String()
+		idx, _ = p.id.Index()
+		t, _ = p.id.Time()
+		r.Hex = p.id.Format(idg.Hex)
+	case "id32":
+		r.Layout = idg.LayoutIndexFirst.String()
+		idx32, _ := p.id32.Index()
+		idx = uint64(idx32)
+		t, _ = p.id32.Time()
+		r.Hex = p.id32.Format(idg.Hex)
+	case "id64":
+		r.Layout = "snowflake"
+		node, seq := sf.Node(p.id64), sf.Sequence(p.id64)
+		r.Node, r.Sequence = &node, &seq
+		t = sf.Time(p.id64)
+		r.Hex = strconv.FormatUint(uint64(p.id64), 16)
+		r.Time = utc(t)
+		return
+	}
+
+	r.Index = &idx
+	r.Time = utc(t)
+}
+
+// utc will return a pointer to the provided time in UTC
+func utc(t time.Time) *time.Time {
+	t = t.UTC()
+	return &t
+}
+
+// newFlagSet will return a new flag set for the provided command
+func newFlagSet(name string, stderr io.Writer) (fs *flag.FlagSet) {
+	fs = flag.NewFlagSet("idg "+name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return
+}
+
+// decodeCmd will decode the provided IDs
+func decodeCmd(args []string, stdin io.Reader, enc *json.Encoder, stderr io.Writer) (err error) {
+	fs := newFlagSet("decode", stderr)
+	from := fs.String("from", "", "codec of the IDs, detected when unset ("+codecList+", int)")
+	sfName := fs.String("snowflake", "twitter", "snowflake layout of numeric IDs (twitter, discord or sonyflake)")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	sf, ok := snowflakes[*sfName]
+	if !ok {
+		return ErrInvalidSnowflake
+	}
+
+	var failed bool
+	if err = forEachInput(fs.Args(), stdin, func(in string) {
+		r := result{Input: in}
+		if p, perr := parse(in, *from); perr != nil {
+			r.Error = perr.Error()
+			failed = true
+		} else {
+			r.Codec = p.codec
+			r.describe(&p, &sf)
+		}
+
+		enc.Encode(&r)
+	}); err != nil {
+		return
+	}
+
+	if failed {
+		err = ErrFailed
+	}
+
+	return
+}
+
+// convertCmd will convert the provided IDs to another codec
+func convertCmd(args []string, stdin io.Reader, enc *json.Encoder, stderr io.Writer) (err error) {
+	fs := newFlagSet("convert", stderr)
+	from := fs.String("from", "", "codec of the IDs, detected when unset ("+codecList+")")
+	to := fs.String("to", "", "codec to convert to ("+codecList+")")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if !isCodec(*to) {
+		return ErrUnknownCodec
+	}
+
+	var failed bool
+	if err = forEachInput(fs.Args(), stdin, func(in string) {
+		r := result{Input: in}
+		p, perr := parse(in, *from)
+		if perr == nil {
+			r.Type, r.Codec = p.typ, *to
+			r.ID, perr = p.format(*to)
+		}
+
+		if perr != nil {
+			r.Error = perr.Error()
+			failed = true
+		}
+
+		enc.Encode(&r)
+	}); err != nil {
+		return
+	}
+
+	if failed {
+		err = ErrFailed
+	}
+
+	return
+}
+
+// genFlags are the flags shared by encode and gen
+type genFlags struct {
+	index  *uint64
+	layout *string
+	width  *int
+	to     *string
+
+	l idg.Layout
+}
+
+// newGenFlags will register the flags shared by encode and gen
+func newGenFlags(fs *flag.FlagSet) (g genFlags) {
+	g.index = fs.Uint64("index", 0, "index of the (first) ID")
+	g.layout = fs.String("layout", "index-first", "layout of 128-bit IDs (index-first or uuidv7)")
+	g.width = fs.Int("width", 128, "width of the IDs (128 or 32)")
+	g.to = fs.String("to", idg.Base64URL.Name(), "codec of the IDs ("+codecList+")")
+	return
+}
+
+// validate will validate the flags, it must be called after the flags are parsed
+func (g *genFlags) validate() (err error) {
+	switch *g.layout {
+	case idg.LayoutIndexFirst.String():
+		g.l = idg.LayoutIndexFirst
+	case idg.LayoutUUIDv7.String():
+		g.l = idg.LayoutUUIDv7
+
+	default:
+		return ErrInvalidLayout
+	}
+
+	if *g.width != 128 && *g.width != 32 {
+		return ErrInvalidWidth
+	}
+
+	if !isCodec(*g.to) {
+		return ErrUnknownCodec
+	}
+
+	return
+}
+
+// result will return the result of the provided index and time
+func (g *genFlags) result(idx uint64, t time.Time) (r result, err error) {
+	var p parsed
+	p.codec = *g.to
+	if *g.width == 32 {
+		if idx > uint64(^uint32(0)) {
+			err = ErrIndexOverflow
+			return
+		}
+
+		p.typ = "id32"
+		p.id32 = idg.Compose32(uint32(idx), t)
+	} else {
+		p.typ = "id"
+		p.id = idg.Compose(idx, t, g.l)
+	}
+
+	if r.ID, err = p.format(*g.to); err != nil {
+		return
+	}
+
+	r.Codec = p.codec
+	r.describe(&p, nil)
+	return
+}
+
+// encodeCmd will encode an ID from an index and time
+func encodeCmd(args []string, enc *json.Encoder, stderr io.Writer) (err error) {
+	fs := newFlagSet("encode", stderr)
+	g := newGenFlags(fs)
+	ts := fs.String("time", "", "time of the ID, RFC 3339 or Unix seconds (defaults to now)")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if err = g.validate(); err != nil {
+		return
+	}
+
+	var t time.Time
+	if t, err = parseTime(*ts); err != nil {
+		return
+	}
+
+	var r result
+	if r, err = g.result(*g.index, t); err != nil {
+		return
+	}
+
+	return enc.Encode(&r)
+}
+
+// genCmd will generate IDs with incrementing indexes
+func genCmd(args []string, enc *json.Encoder, stderr io.Writer) (err error) {
+	fs := newFlagSet("gen", stderr)
+	g := newGenFlags(fs)
+	count := fs.Uint64("count", 1, "number of IDs to generate")
+	if err = fs.Parse(args); err != nil {
+		return
+	}
+
+	if err = g.validate(); err != nil {
+		return
+	}
+
+	for i := uint64(0); i < *count; i++ {
+		var r result
+		if r, err = g.result(*g.index+i, time.Now()); err != nil {
+			return
+		}
+
+		if err = enc.Encode(&r); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// parseTime will parse an RFC 3339 time or Unix timestamp (in seconds), an empty
+// string results in the current time
+func parseTime(in string) (t time.Time, err error) {
+	if in == "" {
+		return time.Now(), nil
+	}
+
+	if ts, perr := strconv.ParseInt(in, 10, 64); perr == nil {
+		return time.Unix(ts, 0), nil
+	}
+
+	if t, err = time.Parse(time.RFC3339Nano, in); err != nil {
+		err = ErrInvalidTime
+	}
+
+	return
+}
@@ -0,0 +1,237 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/PathDNA/idg"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrMissingFiles is returned when no files are provided
+	ErrMissingFiles = errors.Error("no files provided")
+	// ErrInvalidBump is returned when a bump target is not provided
+	ErrInvalidBump = errors.Error("either -to or -by must be provided")
+	// ErrBumpOverflow is returned when a bump would overflow the index
+	ErrBumpOverflow = errors.Error("bump would overflow the index")
+)
+
+const fileUsage = `usage: idg file <command> [flags] <file|dir> ...
+
+commands:
+  show            show the next index of persistent files
+  bump            move the next index of persistent files forward (-to or -by)
+  verify          verify the format and checksum of persistent files
+  migrate-legacy  migrate legacy persistent files to the current format
+  repair          rewrite persistent files which fail their checksum with the index they hold
+  list            list the persistent files within directories
+
+Files locked by another process (e.g. a running generator) are refused.
+`
+
+// fileResult is the JSON output of a single persistent file
+type fileResult struct {
+	Path   string `json:"path"`
+	Key    string `json:"key,omitempty"`
+	Format string `json:"format,omitempty"`
+	// Next index to be issued
+	Index *uint64 `json:"index,omitempty"`
+	// Next index to be issued prior to a bump
+	Previous *uint64 `json:"previous,omitempty"`
+	Migrated *bool   `json:"migrated,omitempty"`
+	Repaired *bool   `json:"repaired,omitempty"`
+	OK       *bool   `json:"ok,omitempty"`
+	Locked   bool    `json:"locked,omitempty"`
+	Error    string  `json:"error,omitempty"`
+}
+
+// set will set the fields of the provided file
+func (r *fileResult) set(f *idg.File) {
+	idx := f.Index()
+	r.Key = f.Key()
+	r.Format = f.Format().String()
+	r.Index = &idx
+}
+
+// setError will set the error of the result
+func (r *fileResult) setError(err error) {
+	r.Locked = err == idg.ErrLocked
+	r.Error = err.Error()
+}
+
+// fileCmd will manage persistent files
+func fileCmd(args []string, enc *json.Encoder, stderr io.Writer) (err error) {
+	if len(args) == 0 {
+		io.WriteString(stderr, fileUsage)
+		return ErrUnknownCommand
+	}
+
+	var (
+		by, to uint64
+		fn     func(f *idg.File, r *fileResult) error
+		// Files which fail their checksum are only opened by repair
+		open = openFile
+	)
+
+	fs := newFlagSet("file "+args[0], stderr)
+	switch args[0] {
+	case "show", "list":
+		fn = showFile
+	case "verify":
+		fn = verifyFile
+	case "migrate-legacy":
+		fn = migrateFile
+	case "repair":
+		open = repairFile
+		fn = showFile
+	case "bump":
+		fs.Uint64Var(&to, "to", 0, "next index to be issued")
+		fs.Uint64Var(&by, "by", 0, "number of indexes to skip")
+		fn = func(f *idg.File, r *fileResult) error { return bumpFile(f, r, to, by) }
+
+	default:
+		io.WriteString(stderr, fileUsage)
+		return ErrUnknownCommand
+	}
+
+	if err = fs.Parse(args[1:]); err != nil {
+		return
+	}
+
+	if args[0] == "bump" && (to == 0) == (by == 0) {
+		return ErrInvalidBump
+	}
+
+	fps := fs.Args()
+	if args[0] == "list" {
+		if fps, err = listFiles(fps); err != nil {
+			return
+		}
+	} else if len(fps) == 0 {
+		return ErrMissingFiles
+	}
+
+	var failed bool
+	for _, fp := range fps {
+		r := fileResult{Path: fp, Key: strings.TrimSuffix(filepath.Base(fp), idg.FileExt)}
+		if ferr := withFile(fp, &r, open, fn); ferr != nil {
+			r.setError(ferr)
+			if args[0] == "verify" {
+				ok := false
+				r.OK = &ok
+			}
+
+			// Locked files are reported by list rather than treated as a failure
+			failed = failed || args[0] != "list" || ferr != idg.ErrLocked
+		}
+
+		if err = enc.Encode(&r); err != nil {
+			return
+		}
+	}
+
+	if failed {
+		err = ErrFailed
+	}
+
+	return
+}
+
+// fileOpener will open the provided file
+type fileOpener func(fp string, r *fileResult) (*idg.File, error)
+
+// withFile will open the provided file and call fn
+func withFile(fp string, r *fileResult, open fileOpener, fn func(f *idg.File, r *fileResult) error) (err error) {
+	var f *idg.File
+	if f, err = open(fp, r); err != nil {
+		return
+	}
+	defer f.Close()
+
+	r.set(f)
+	if err = fn(f, r); err != nil {
+		return
+	}
+
+	return f.Close()
+}
+
+// listFiles will return the persistent files within the provided directories
+func listFiles(dirs []string) (fps []string, err error) {
+	if len(dirs) == 0 {
+		dirs = []string{"."}
+	}
+
+	for _, dir := range dirs {
+		var dfps []string
+		if dfps, err = idg.ListFiles(dir); err != nil {
+			return
+		}
+
+		fps = append(fps, dfps...)
+	}
+
+	return
+}
+
+// showFile will report the file as-is
+func showFile(f *idg.File, r *fileResult) (err error) {
+	return
+}
+
+// verifyFile will report whether or not a file is valid
+// Note: Invalid files fail to open, legacy files are valid but have no checksum
+func verifyFile(f *idg.File, r *fileResult) (err error) {
+	ok := true
+	r.OK = &ok
+	return
+}
+
+// migrateFile will migrate a legacy file to the current format
+func migrateFile(f *idg.File, r *fileResult) (err error) {
+	var migrated bool
+	if migrated, err = f.Migrate(); err != nil {
+		return
+	}
+
+	r.set(f)
+	r.Migrated = &migrated
+	return
+}
+
+// openFile will open a valid file
+func openFile(fp string, r *fileResult) (f *idg.File, err error) {
+	return idg.OpenFile(fp)
+}
+
+// repairFile will open a file, rewriting it when it fails its checksum
+func repairFile(fp string, r *fileResult) (f *idg.File, err error) {
+	var repaired bool
+	if f, repaired, err = idg.RepairFile(fp); err != nil {
+		return
+	}
+
+	r.Repaired = &repaired
+	return
+}
+
+// bumpFile will move the next index of a file forward to or by the provided value
+func bumpFile(f *idg.File, r *fileResult, to, by uint64) (err error) {
+	prev := f.Index()
+	if by > 0 {
+		if to = prev + by; to < prev {
+			return ErrBumpOverflow
+		}
+	}
+
+	if err = f.Bump(to); err != nil {
+		return
+	}
+
+	r.set(f)
+	r.Previous = &prev
+	return
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PathDNA/idg"
+	"github.com/itsmontoya/mum"
+)
+
+func runFileTest(t *testing.T, args ...string) (results []fileResult, err error) {
+	var stdout bytes.Buffer
+	err = run(append([]string{"file"}, args...), strings.NewReader(""), &stdout, io.Discard)
+
+	dec := json.NewDecoder(&stdout)
+	for {
+		var r fileResult
+		if derr := dec.Decode(&r); derr == io.EOF {
+			break
+		} else if derr != nil {
+			t.Fatal(derr)
+		}
+
+		results = append(results, r)
+	}
+
+	return
+}
+
+func newTestFiles(t *testing.T) (dir string) {
+	dir, err := os.MkdirTemp("", "idg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	p, err := idg.NewPersistent("orders", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 5; i++ {
+		if _, err = p.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Legacy file with a last issued index of 9
+	var bw mum.BinaryWriter
+	if err = os.WriteFile(filepath.Join(dir, "users.idg"), bw.Uint64(9), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestFileShowBump(t *testing.T) {
+	dir := newTestFiles(t)
+	fp := filepath.Join(dir, "orders.idg")
+
+	rs, err := runFileTest(t, "show", fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := rs[0]; r.Key != "orders" || r.Format != "v1" || *r.Index != 5 {
+		t.Fatalf("invalid result: %+v", r)
+	}
+
+	if _, err = runFileTest(t, "bump", "-to", "3", fp); err != ErrFailed {
+		t.Fatalf("invalid error, expected %v and received %v", ErrFailed, err)
+	}
+
+	if rs, err = runFileTest(t, "bump", "-by", "100", fp); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := rs[0]; *r.Previous != 5 || *r.Index != 105 {
+		t.Fatalf("invalid result: %+v", r)
+	}
+
+	if _, err = runFileTest(t, "bump", fp); err != ErrInvalidBump {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidBump, err)
+	}
+}
+
+func TestFileMigrateVerify(t *testing.T) {
+	dir := newTestFiles(t)
+	fp := filepath.Join(dir, "users.idg")
+
+	rs, err := runFileTest(t, "migrate-legacy", fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if r := rs[0]; !*r.Migrated || r.Format != "v1" || *r.Index != 10 {
+		t.Fatalf("invalid result: %+v", r)
+	}
+
+	if rs, err = runFileTest(t, "migrate-legacy", fp); err != nil || *rs[0].Migrated {
+		t.Fatalf("invalid second migration: %+v / %v", rs, err)
+	}
+
+	// Corrupt the checksum
+	b, err := os.ReadFile(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b[15] ^= 1
+	if err = os.WriteFile(fp, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if rs, err = runFileTest(t, "verify", fp); err != ErrFailed {
+		t.Fatalf("invalid error, expected %v and received %v", ErrFailed, err)
+	}
+
+	if r := rs[0]; *r.OK || r.Error != idg.ErrFileChecksum.Error() {
+		t.Fatalf("invalid result: %+v", r)
+	}
+
+	// Repair rewrites the file with the index it holds
+	if rs, err = runFileTest(t, "repair", fp); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := rs[0]; !*r.Repaired || r.Format != "v1" || *r.Index != 10 {
+		t.Fatalf("invalid result: %+v", r)
+	}
+
+	if rs, err = runFileTest(t, "verify", fp); err != nil || !*rs[0].OK {
+		t.Fatalf("invalid verification after repair: %+v / %v", rs, err)
+	}
+
+	if rs, err = runFileTest(t, "repair", fp); err != nil || *rs[0].Repaired {
+		t.Fatalf("invalid second repair: %+v / %v", rs, err)
+	}
+}
+
+func TestFileListLocked(t *testing.T) {
+	dir := newTestFiles(t)
+	p, err := idg.NewPersistent("orders", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	rs, err := runFileTest(t, "list", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rs) != 2 {
+		t.Fatalf("invalid number of results, expected %d and received %d", 2, len(rs))
+	}
+
+	if r := rs[0]; r.Key != "orders" || !r.Locked {
+		t.Fatalf("invalid result: %+v", r)
+	}
+
+	if r := rs[1]; r.Key != "users" || r.Format != "legacy" || *r.Index != 10 {
+		t.Fatalf("invalid result: %+v", r)
+	}
+
+	// Locked files are refused
+	if _, err = runFileTest(t, "bump", "-by", "1", filepath.Join(dir, "orders.idg")); err != ErrFailed {
+		t.Fatalf("invalid error, expected %v and received %v", ErrFailed, err)
+	}
+}
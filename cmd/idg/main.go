@@ -0,0 +1,119 @@
+// Command idg inspects, decodes and generates IDs
+//
+// Usage:
+//
+//	idg decode [-from codec] [-snowflake twitter|discord|sonyflake] [id ...]
+//	idg encode [-index n] [-time t] [-layout index-first|uuidv7] [-width 128|32] [-to codec]
+//	idg gen [-count n] [-index n] [-layout index-first|uuidv7] [-width 128|32] [-to codec]
+//	idg convert -to codec [-from codec] [id ...]
+//	idg file show|bump|verify|migrate-legacy|repair [-to n] [-by n] <file> ...
+//	idg file list [dir ...]
+//
+// When no IDs are provided as arguments, IDs are read from stdin (one per line).
+// Results are written to stdout as JSON, one object per line
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrUnknownCommand is returned when an unknown subcommand is provided
+	ErrUnknownCommand = errors.Error("unknown command")
+	// ErrFailed is returned when at least one input could not be processed
+	ErrFailed = errors.Error("one or more inputs failed")
+)
+
+const usage = `usage: idg <command> [flags] [id ...]
+
+commands:
+  decode   decode IDs (index, time, node, sequence, layout and codec)
+  encode   encode an ID from an index and time
+  gen      generate IDs
+  convert  convert IDs to another codec
+  file     manage persistent counter files (show, bump, verify, migrate-legacy, repair and list)
+
+codecs: ` + codecList + `
+
+IDs are read from stdin (one per line) when none are provided as arguments.
+Run "idg <command> -h" for the flags of a command.
+`
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr); err != nil {
+		if err != ErrFailed {
+			fmt.Fprintln(os.Stderr, "idg:", err)
+		}
+
+		os.Exit(1)
+	}
+}
+
+// run will run the provided command line
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) (err error) {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+		return ErrUnknownCommand
+	}
+
+	enc := json.NewEncoder(stdout)
+	switch args[0] {
+	case "decode":
+		return decodeCmd(args[1:], stdin, enc, stderr)
+	case "encode":
+		return encodeCmd(args[1:], enc, stderr)
+	case "gen":
+		return genCmd(args[1:], enc, stderr)
+	case "convert":
+		return convertCmd(args[1:], stdin, enc, stderr)
+	case "file":
+		return fileCmd(args[1:], enc, stderr)
+	case "help", "-h", "-help", "--help":
+		fmt.Fprint(stdout, usage)
+		return
+
+	default:
+		fmt.Fprint(stderr, usage)
+		return ErrUnknownCommand
+	}
+}
+
+// forEachInput will call fn for each argument, or for each non-empty line of stdin when no
+// arguments are provided
+// Note: Lines which are JSON objects (e.g. the output of gen) are reduced to their "id" field
+// (or "input" when no ID is set) so commands can be chained within pipelines
+func forEachInput(args []string, stdin io.Reader, fn func(in string)) (err error) {
+	if len(args) > 0 {
+		for _, arg := range args {
+			fn(arg)
+		}
+
+		return
+	}
+
+	scn := bufio.NewScanner(stdin)
+	for scn.Scan() {
+		line := strings.TrimSpace(scn.Text())
+		if strings.HasPrefix(line, "{") {
+			var r result
+			if json.Unmarshal([]byte(line), &r) == nil {
+				if line = r.ID; line == "" {
+					line = r.Input
+				}
+			}
+		}
+
+		if line != "" {
+			fn(line)
+		}
+	}
+
+	return scn.Err()
+}
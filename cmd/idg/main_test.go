@@ -0,0 +1,238 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/PathDNA/idg"
+)
+
+func runTest(t *testing.T, stdin string, args ...string) (results []result, err error) {
+	var stdout bytes.Buffer
+	err = run(args, strings.NewReader(stdin), &stdout, io.Discard)
+
+	dec := json.NewDecoder(&stdout)
+	for {
+		var r result
+		if derr := dec.Decode(&r); derr == io.EOF {
+			break
+		} else if derr != nil {
+			t.Fatal(derr)
+		}
+
+		results = append(results, r)
+	}
+
+	return
+}
+
+func TestDecode(t *testing.T) {
+	ts := time.Unix(1700000000, 0).UTC()
+	id := idg.Compose(1337, ts, idg.LayoutIndexFirst)
+	v7 := idg.Compose(42, ts, idg.LayoutUUIDv7)
+	id32 := idg.Compose32(7, ts)
+
+	tcs := []struct {
+		in     string
+		typ    string
+		codec  string
+		layout string
+		index  uint64
+	}{
+		{id.String(), "id", "base64url", "index-first", 1337},
+		{id.Format(idg.Hex), "id", "hex", "index-first", 1337},
+		{id.Format(idg.Base32), "id", "base32", "index-first", 1337},
+		{id.HumanString(), "id", "human", "index-first", 1337},
+		{v7.UUIDString(), "id", "uuid", "uuidv7", 42},
+		{id32.String(), "id32", "base64url", "index-first", 7},
+		{id.Format(idg.Base58), "id", "base58", "index-first", 1337},
+		{id.Format(idg.Base62), "id", "base62", "index-first", 1337},
+		{v7.Format(idg.Base58), "id", "base58", "uuidv7", 42},
+		{v7.Format(idg.Base62), "id", "base62", "uuidv7", 42},
+	}
+
+	for _, tc := range tcs {
+		rs, err := runTest(t, "", "decode", tc.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.in, err)
+		}
+
+		r := rs[0]
+		if r.Type != tc.typ || r.Codec != tc.codec || r.Layout != tc.layout {
+			t.Fatalf("%s: invalid result: %+v", tc.in, r)
+		}
+
+		if r.Index == nil || *r.Index != tc.index {
+			t.Fatalf("%s: invalid index, expected %d and received %v", tc.in, tc.index, r.Index)
+		}
+
+		if r.Time == nil || !r.Time.Equal(ts) {
+			t.Fatalf("%s: invalid time, expected %v and received %v", tc.in, ts, r.Time)
+		}
+	}
+}
+
+func TestDecodeNumeric(t *testing.T) {
+	// All-digit hex IDs and ID32s are decoded as IDs rather than snowflake ID64s
+	tcs := []struct {
+		in  string
+		typ string
+	}{
+		{"12345678901234560012345600000000", "id"},
+		{"1234567890123456", "id32"},
+	}
+
+	for _, tc := range tcs {
+		rs, err := runTest(t, "", "decode", tc.in)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.in, err)
+		}
+
+		if r := rs[0]; r.Type != tc.typ || r.Codec != "hex" {
+			t.Fatalf("%s: invalid result: %+v", tc.in, r)
+		}
+	}
+
+	id32, err := idg.ParseWith32(idg.Hex, "1234567890123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rs, err := runTest(t, "", "decode", "1234567890123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, _ := id32.Index()
+	if r := rs[0]; r.Index == nil || *r.Index != uint64(idx) {
+		t.Fatalf("invalid index, expected %d and received %v", idx, r.Index)
+	}
+
+	// The int codec decodes numeric input as an ID64
+	if rs, err = runTest(t, "", "decode", "-from", "int", "1234567890123456"); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := rs[0]; r.Type != "id64" {
+		t.Fatalf("invalid result: %+v", r)
+	}
+
+	// Numeric input which is not an ID is decoded as an ID64
+	if rs, err = runTest(t, "", "decode", "175928847299117063"); err != nil {
+		t.Fatal(err)
+	}
+
+	if r := rs[0]; r.Type != "id64" {
+		t.Fatalf("invalid result: %+v", r)
+	}
+}
+
+func TestDecodeSnowflake(t *testing.T) {
+	// Example from the Discord documentation
+	rs, err := runTest(t, "", "decode", "-snowflake", "discord", "175928847299117063")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := rs[0]
+	if r.Type != "id64" || r.Layout != "snowflake" || *r.Node != 1<<5 || *r.Sequence != 7 {
+		t.Fatalf("invalid result: %+v", r)
+	}
+
+	if ms := r.Time.UnixMilli(); ms != 1462015105796 {
+		t.Fatalf("invalid time, expected %d and received %d", int64(1462015105796), ms)
+	}
+}
+
+func TestDecodeStdin(t *testing.T) {
+	id := idg.Compose(1, time.Now(), idg.LayoutIndexFirst)
+	rs, err := runTest(t, id.String()+"\n\nnot-an-id\n", "decode")
+	if err != ErrFailed {
+		t.Fatalf("invalid error, expected %v and received %v", ErrFailed, err)
+	}
+
+	if len(rs) != 2 {
+		t.Fatalf("invalid number of results, expected %d and received %d", 2, len(rs))
+	}
+
+	if rs[0].Error != "" || rs[1].Error == "" {
+		t.Fatalf("invalid results: %+v", rs)
+	}
+}
+
+func TestEncodeConvert(t *testing.T) {
+	rs, err := runTest(t, "", "encode", "-index", "1337", "-time", "2023-11-14T22:13:20Z", "-layout", "uuidv7", "-to", "uuid")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := idg.Compose(1337, time.Unix(1700000000, 0), idg.LayoutUUIDv7)
+	if rs[0].ID != expected.UUIDString() {
+		t.Fatalf("invalid ID, expected %s and received %s", expected.UUIDString(), rs[0].ID)
+	}
+
+	if rs, err = runTest(t, rs[0].ID+"\n", "convert", "-to", "hex"); err != nil {
+		t.Fatal(err)
+	}
+
+	if rs[0].ID != expected.Format(idg.Hex) {
+		t.Fatalf("invalid ID, expected %s and received %s", expected.Format(idg.Hex), rs[0].ID)
+	}
+
+	// Output of a previous command is accepted as input
+	var out bytes.Buffer
+	json.NewEncoder(&out).Encode(&rs[0])
+	if rs, err = runTest(t, out.String(), "convert", "-to", "base32"); err != nil {
+		t.Fatal(err)
+	}
+
+	if rs[0].ID != expected.Format(idg.Base32) {
+		t.Fatalf("invalid ID, expected %s and received %s", expected.Format(idg.Base32), rs[0].ID)
+	}
+
+	// Base58 and base62 input shares a length and alphabet with base64url
+	for _, enc := range []idg.Encoding{idg.Base58, idg.Base62} {
+		if rs, err = runTest(t, "", "convert", "-to", "hex", expected.Format(enc)); err != nil {
+			t.Fatal(err)
+		}
+
+		if rs[0].ID != expected.Format(idg.Hex) {
+			t.Fatalf("invalid %s conversion, expected %s and received %s", enc.Name(), expected.Format(idg.Hex), rs[0].ID)
+		}
+	}
+
+	id32 := idg.Compose32(1, time.Now())
+	if _, err = runTest(t, "", "convert", "-to", "uuid", id32.String()); err != ErrFailed {
+		t.Fatalf("invalid error, expected %v and received %v", ErrFailed, err)
+	}
+}
+
+func TestGen(t *testing.T) {
+	rs, err := runTest(t, "", "gen", "-count", "3", "-index", "10", "-width", "32", "-to", "base32")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rs) != 3 {
+		t.Fatalf("invalid number of results, expected %d and received %d", 3, len(rs))
+	}
+
+	for i, r := range rs {
+		id, err := idg.ParseWith32(idg.Base32, r.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if idx, _ := id.Index(); idx != uint32(10+i) {
+			t.Fatalf("invalid index, expected %d and received %d", 10+i, idx)
+		}
+	}
+
+	if _, err = runTest(t, "", "gen", "-layout", "invalid"); err != ErrInvalidLayout {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidLayout, err)
+	}
+}
@@ -0,0 +1,56 @@
+// Command idgd is an HTTP ID issuing service backed by persistent generators
+//
+// Usage:
+//
+//	idgd -addr :8080 -dir ./data
+//
+// IDs are issued with POST /keys/{key}/next?count=N&width=32|128
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/PathDNA/idg/idgd"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	dir := flag.String("dir", "./data", "directory to store persistent generators within")
+	flag.Parse()
+
+	srv := idgd.NewServer(idgd.PersistentOpener(*dir))
+	hs := &http.Server{Addr: *addr, Handler: srv}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		sc := make(chan os.Signal, 1)
+		signal.Notify(sc, os.Interrupt, syscall.SIGTERM)
+		<-sc
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		// Stop accepting requests and wait for in-flight requests before closing generators
+		if err := hs.Shutdown(ctx); err != nil {
+			log.Printf("error shutting down HTTP server: %v", err)
+		}
+	}()
+
+	log.Printf("idgd listening on %s", *addr)
+	if err := hs.ListenAndServe(); err != http.ErrServerClosed {
+		log.Fatal(err)
+	}
+
+	<-done
+	// Ensure all generators are flushed and closed
+	if err := srv.Close(); err != nil {
+		log.Fatalf("error closing generators: %v", err)
+	}
+}
@@ -0,0 +1,145 @@
+package idg
+
+import (
+	"bytes"
+	"cmp"
+
+	"github.com/itsmontoya/mum"
+)
+
+// Compare will compare two IDs, returning -1 if a is less than b, 0 if they are equal
+// and +1 if a is greater than b. It can be utilized directly with slices.SortFunc
+// Note: IDs are ordered by the leading field of their layout. Index-first IDs are
+// ordered by index, then time. UUIDv7 IDs are ordered by time, then index (which
+// matches their byte order). When layouts differ, index-first IDs are ordered first
+func Compare(a, b ID) int {
+	la, lb := a.Layout(), b.Layout()
+	if la != lb {
+		return cmp.Compare(la, lb)
+	}
+
+	if la == LayoutUUIDv7 {
+		return bytes.Compare(a[:], b[:])
+	}
+
+	// Helper for binary decoding
+	var br mum.BinaryReader
+	ai, _ := br.Uint64(a[:8])
+	bi, _ := br.Uint64(b[:8])
+	if c := cmp.Compare(ai, bi); c != 0 {
+		return c
+	}
+
+	at, _ := br.Int64(a[8:])
+	bt, _ := br.Int64(b[8:])
+	return cmp.Compare(at, bt)
+}
+
+// Compare32 will compare two ID32s, returning -1 if a is less than b, 0 if they are
+// equal and +1 if a is greater than b. It can be utilized directly with slices.SortFunc
+// Note: ID32s are ordered by index, then time
+func Compare32(a, b ID32) int {
+	// Helper for binary decoding
+	var br mum.BinaryReader
+	ai, _ := br.Uint32(a[:4])
+	bi, _ := br.Uint32(b[:4])
+	if c := cmp.Compare(ai, bi); c != 0 {
+		return c
+	}
+
+	at, _ := br.Uint32(a[4:])
+	bt, _ := br.Uint32(b[4:])
+	return cmp.Compare(at, bt)
+}
+
+// Min will return the lowest of the provided IDs, an empty ID is returned when no IDs are provided
+func Min(ids ...ID) (lowest ID) {
+	for i, id := range ids {
+		if i == 0 || Compare(id, lowest) < 0 {
+			lowest = id
+		}
+	}
+
+	return
+}
+
+// Max will return the highest of the provided IDs, an empty ID is returned when no IDs are provided
+func Max(ids ...ID) (highest ID) {
+	for i, id := range ids {
+		if i == 0 || Compare(id, highest) > 0 {
+			highest = id
+		}
+	}
+
+	return
+}
+
+// Min32 will return the lowest of the provided ID32s, an empty ID32 is returned when no IDs are provided
+func Min32(ids ...ID32) (lowest ID32) {
+	for i, id := range ids {
+		if i == 0 || Compare32(id, lowest) < 0 {
+			lowest = id
+		}
+	}
+
+	return
+}
+
+// Max32 will return the highest of the provided ID32s, an empty ID32 is returned when no IDs are provided
+func Max32(ids ...ID32) (highest ID32) {
+	for i, id := range ids {
+		if i == 0 || Compare32(id, highest) > 0 {
+			highest = id
+		}
+	}
+
+	return
+}
+
+// Compare will compare an ID to another, see Compare for ordering semantics
+// Note: A nil ID is treated as an empty ID
+func (id *ID) Compare(other ID) int {
+	if id == nil {
+		return Compare(emptyID, other)
+	}
+
+	return Compare(*id, other)
+}
+
+// Less will return whether or not an ID is ordered before another
+func (id *ID) Less(other ID) bool {
+	return id.Compare(other) < 0
+}
+
+// Equal will return whether or not an ID is equal to another
+func (id *ID) Equal(other ID) bool {
+	if id == nil {
+		return other == emptyID
+	}
+
+	return *id == other
+}
+
+// Compare will compare an ID32 to another, see Compare32 for ordering semantics
+// Note: A nil ID32 is treated as an empty ID32
+func (id *ID32) Compare(other ID32) int {
+	if id == nil {
+		return Compare32(emptyID32, other)
+	}
+
+	return Compare32(*id, other)
+}
+
+// Less will return whether or not an ID32 is ordered before another
+func (id *ID32) Less(other ID32) bool {
+	return id.Compare(other) < 0
+}
+
+// Equal will return whether or not an ID32 is equal to another
+func (id *ID32) Equal(other ID32) bool {
+	if id == nil {
+		return other == emptyID32
+	}
+
+	return *id == other
+}
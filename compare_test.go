@@ -0,0 +1,60 @@
+package idg
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCompare(t *testing.T) {
+	// Index 256 is stored as little-endian, so byte comparison would order it before index 1
+	ids := []ID{newID(256, 10), newID(1, 20), newID(1, 10), newID(2, 0)}
+	slices.SortFunc(ids, Compare)
+	expected := []ID{newID(1, 10), newID(1, 20), newID(2, 0), newID(256, 10)}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Fatalf("invalid order at %d, expected %v and received %v", i, expected[i], ids[i])
+		}
+	}
+
+	// UUIDv7 IDs are ordered by time, then index
+	v7 := []ID{newUUIDv7(1, 2000), newUUIDv7(2, 1000), newUUIDv7(1, 1000)}
+	slices.SortFunc(v7, Compare)
+	expected = []ID{newUUIDv7(1, 1000), newUUIDv7(2, 1000), newUUIDv7(1, 2000)}
+	for i := range v7 {
+		if v7[i] != expected[i] {
+			t.Fatalf("invalid order at %d, expected %v and received %v", i, expected[i], v7[i])
+		}
+	}
+
+	a, b := newID(1, 10), newID(2, 0)
+	if !a.Less(b) || b.Less(a) || !a.Equal(a) || a.Equal(b) {
+		t.Fatal("invalid comparison results")
+	}
+
+	if min := Min(ids...); min != newID(1, 10) {
+		t.Fatalf("invalid min, expected %v and received %v", newID(1, 10), min)
+	}
+
+	if max := Max(ids...); max != newID(256, 10) {
+		t.Fatalf("invalid max, expected %v and received %v", newID(256, 10), max)
+	}
+}
+
+func TestCompare32(t *testing.T) {
+	ids := []ID32{newID32(256, 10), newID32(1, 20), newID32(1, 10)}
+	slices.SortFunc(ids, Compare32)
+	expected := []ID32{newID32(1, 10), newID32(1, 20), newID32(256, 10)}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			t.Fatalf("invalid order at %d, expected %v and received %v", i, expected[i], ids[i])
+		}
+	}
+
+	if min := Min32(ids...); min != newID32(1, 10) {
+		t.Fatalf("invalid min, expected %v and received %v", newID32(1, 10), min)
+	}
+
+	if max := Max32(ids...); max != newID32(256, 10) {
+		t.Fatalf("invalid max, expected %v and received %v", newID32(256, 10), max)
+	}
+}
@@ -0,0 +1,74 @@
+package idg
+
+import (
+	"math"
+
+	"github.com/itsmontoya/mum"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrIndexOverflow is returned when an index does not fit within 32 bits
+	ErrIndexOverflow = errors.Error("index does not fit within 32 bits")
+	// ErrTimePrecision is returned when a timestamp has sub-second precision which would be lost
+	ErrTimePrecision = errors.Error("timestamp has sub-second precision")
+)
+
+// To32 will return the ID32 representation of an ID
+// Note: An error is returned when the conversion would lose information, which is
+// when the index exceeds 32 bits, the timestamp is outside of the uint32 range or
+// the timestamp has sub-second precision (UUIDv7 layout)
+func (id *ID) To32() (out ID32, err error) {
+	if id == nil {
+		err = ErrEmptyID
+		return
+	}
+
+	var idx uint64
+	if idx, err = id.Index(); err != nil {
+		return
+	}
+
+	if idx > math.MaxUint32 {
+		err = ErrIndexOverflow
+		return
+	}
+
+	var ts int64
+	if id.Layout() == LayoutUUIDv7 {
+		ms := uuidV7Time(id).UnixMilli()
+		if ms%1000 != 0 {
+			err = ErrTimePrecision
+			return
+		}
+
+		ts = ms / 1000
+	} else {
+		// Helper for binary decoding
+		var br mum.BinaryReader
+		// Grab the Unix timestamp from the last 8 bytes
+		if ts, err = br.Int64(id[8:]); err != nil {
+			return
+		}
+	}
+
+	if ts < 0 || ts > math.MaxUint32 {
+		err = ErrTimeOverflow
+		return
+	}
+
+	out = newID32(uint32(idx), ts)
+	return
+}
+
+// To128 will return the ID representation of an ID32
+// Note: This conversion is lossless, the returned ID utilizes the index-first layout
+func (id *ID32) To128() (out ID) {
+	if id == nil {
+		return
+	}
+
+	idx, _ := id.Index()
+	t, _ := id.Time()
+	return newID(uint64(idx), t.Unix())
+}
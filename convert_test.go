@@ -0,0 +1,49 @@
+package idg
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTo32(t *testing.T) {
+	var (
+		id32 ID32
+		err  error
+	)
+
+	id := newID(1337, 1700000000)
+	if id32, err = id.To32(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex32(id32, 1337); err != nil {
+		t.Fatal(err)
+	}
+
+	// Widening should be lossless
+	if nid := id32.To128(); nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id, nid)
+	}
+
+	overflow := newID(math.MaxUint32+1, 1700000000)
+	if _, err = overflow.To32(); err != ErrIndexOverflow {
+		t.Fatalf("invalid error, expected %v and received %v", ErrIndexOverflow, err)
+	}
+
+	future := newID(1337, math.MaxUint32+1)
+	if _, err = future.To32(); err != ErrTimeOverflow {
+		t.Fatalf("invalid error, expected %v and received %v", ErrTimeOverflow, err)
+	}
+
+	v7 := newUUIDv7(1337, 1700000000123)
+	if _, err = v7.To32(); err != ErrTimePrecision {
+		t.Fatalf("invalid error, expected %v and received %v", ErrTimePrecision, err)
+	}
+
+	v7 = newUUIDv7(1337, 1700000000000)
+	if id32, err = v7.To32(); err != nil {
+		t.Fatal(err)
+	} else if nid := id32.To128(); nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id, nid)
+	}
+}
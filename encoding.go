@@ -0,0 +1,440 @@
+package idg
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"math"
+	"slices"
+
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidEncoding is returned when a string cannot be decoded by any encoding
+	ErrInvalidEncoding = errors.Error("invalid encoding")
+	// ErrAmbiguousEncoding is returned when a string is a valid encoding of different IDs
+	// within multiple encodings
+	ErrAmbiguousEncoding = errors.Error("ambiguous encoding, the encoding must be specified")
+)
+
+const (
+	// Crockford's base32 alphabet
+	crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+	// Bitcoin base58 alphabet
+	base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	// Base62 alphabet
+	base62Alphabet = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	// Hex is the lowercase hexadecimal encoding
+	Hex Encoding = hexEncoding{}
+	// Base32 is Crockford's base32 encoding, decoding is case-insensitive and
+	// ambiguous characters (O, I and L) are accepted. Non-zero trailing bits are rejected
+	Base32 Encoding = base32Encoding{base32.NewEncoding(crockfordAlphabet).WithPadding(base32.NoPadding)}
+	// Base58 is the fixed-width Bitcoin base58 encoding
+	Base58 Encoding = newBaseNEncoding("base58", base58Alphabet)
+	// Base62 is the fixed-width base62 encoding
+	Base62 Encoding = newBaseNEncoding("base62", base62Alphabet)
+	// Base64URL is the unpadded URL-safe base64 encoding, this is the default encoding
+	// Note: Decoding is strict, non-zero trailing bits are rejected
+	Base64URL Encoding = base64Encoding{"base64url", base64.RawURLEncoding.Strict()}
+
+	// Encodings are the built-in encodings in the order utilized by ParseAny
+	Encodings = []Encoding{Base64URL, Base62, Base58, Base32, Hex}
+)
+
+var (
+	// Encoding utilized by ID
+	idEnc = Base64URL
+	// Encoding utilized by ID32
+	id32Enc = Base64URL
+)
+
+// SetEncoding will set the encoding utilized by ID for String, Parse and JSON
+// Note: This is not thread-safe and is intended to be called during initialization
+func SetEncoding(enc Encoding) {
+	idEnc = enc
+}
+
+// SetEncoding32 will set the encoding utilized by ID32 for String, Parse32 and JSON
+// Note: This is not thread-safe and is intended to be called during initialization
+func SetEncoding32(enc Encoding) {
+	id32Enc = enc
+}
+
+// Encoding is a text codec for IDs
+// Note: Encoded output must be printable ASCII which does not require JSON escaping
+type Encoding interface {
+	// Name will return the name of the encoding (e.g. "base64url")
+	Name() string
+	// EncodedLen will return the length of the encoding of n bytes
+	EncodedLen(n int) int
+	// Encode will encode src into dst, dst must be EncodedLen(len(src)) bytes
+	Encode(dst, src []byte)
+	// Decode will decode src into dst, src must be EncodedLen(len(dst)) bytes
+	Decode(dst, src []byte) error
+}
+
+// ParseWith will parse a string id utilizing the provided encoding
+func ParseWith(enc Encoding, in string) (id ID, err error) {
+	err = decode(enc, id[:], []byte(in))
+	return
+}
+
+// ParseWith32 will parse a 32-bit string id utilizing the provided encoding
+func ParseWith32(enc Encoding, in string) (id ID32, err error) {
+	err = decode(enc, id[:], []byte(in))
+	return
+}
+
+// ParseAny will parse a string id of any built-in encoding, canonical UUID strings and
+// human-friendly strings. The encoding is detected by alphabet and every encoding must
+// decode strictly (the string must be the canonical encoding of the decoded ID)
+// Note: Some encodings share a length and alphabet (base64url, base62 and base58). When
+// a string is valid for multiple encodings, the encodings which decode into the structure of
+// a generated ID (UUIDv7 or index-first) are kept. The encoding set by SetEncoding takes
+// priority over the remaining encodings, ErrAmbiguousEncoding is returned when it is not one
+// of the remaining encodings
+func ParseAny(in string) (id ID, err error) {
+	if len(in) == uuidStrLen {
+		if id, err = ParseUUID(in); err == nil {
+			return
+		}
+	}
+
+	var perr error
+	if _, perr = detectEncoding(id[:], idEnc, in); perr == nil {
+		return
+	}
+
+	if id, err = ParseHuman(in); err == nil {
+		return
+	}
+
+	err = parseAnyError(perr)
+	return
+}
+
+// ParseAny32 will parse a 32-bit string id of any built-in encoding and human-friendly strings
+// Note: See ParseAny for the priority of encodings which share a length. ID32 has no structure
+// to tell encodings apart, the encoding set by SetEncoding32 takes priority whenever it matches
+func ParseAny32(in string) (id ID32, err error) {
+	var perr error
+	if _, perr = detectEncoding(id[:], id32Enc, in); perr == nil {
+		return
+	}
+
+	if id, err = ParseHuman32(in); err == nil {
+		return
+	}
+
+	err = parseAnyError(perr)
+	return
+}
+
+// DetectEncoding will return the encoding of a string id (ID or ID32), see ParseAny for
+// the priority of encodings which share a length
+func DetectEncoding(in string) (enc Encoding, err error) {
+	var id ID
+	if enc, err = detectEncoding(id[:], idEnc, in); err != ErrInvalidEncoding {
+		return
+	}
+
+	var id32 ID32
+	return detectEncoding(id32[:], id32Enc, in)
+}
+
+// detectEncoding will decode the provided string into dst with the single encoding which
+// decodes the string, see ParseAny for the priority of encodings which share a length
+func detectEncoding(dst []byte, preferred Encoding, in string) (enc Encoding, err error) {
+	type match struct {
+		enc Encoding
+		b   [16]byte
+	}
+
+	if len(dst) > len(match{}.b) {
+		return nil, ErrInvalidLength
+	}
+
+	var (
+		ms  [8]match
+		n   int
+		src = []byte(in)
+	)
+
+	// Every encoding decodes into a scratch buffer so dst is only modified on success
+	for i := -1; i < len(Encodings); i++ {
+		e := preferred
+		if i >= 0 {
+			if e = Encodings[i]; e == preferred {
+				continue
+			}
+		}
+
+		if decode(e, ms[n].b[:len(dst)], src) == nil {
+			ms[n].enc = e
+			n++
+		}
+	}
+
+	if n > 1 && len(dst) == len(ID{}) {
+		// Most strings which are valid for multiple encodings decode into random bytes,
+		// keep the decoded IDs which have the structure of a generated ID
+		valid := 0
+		for i := 0; i < n; i++ {
+			if isPlausibleID((*ID)(ms[i].b[:])) {
+				ms[valid] = ms[i]
+				valid++
+			}
+		}
+
+		if valid > 0 {
+			n = valid
+		}
+	}
+
+	switch {
+	case n == 0:
+		return nil, ErrInvalidEncoding
+	case n > 1 && ms[0].enc != preferred:
+		// The string is the canonical encoding of multiple IDs
+		return nil, ErrAmbiguousEncoding
+	}
+
+	copy(dst, ms[0].b[:len(dst)])
+	return ms[0].enc, nil
+}
+
+// isPlausibleID will return whether or not an ID has the structure of a generated ID, either
+// a UUIDv7 or an index-first ID with a timestamp prior to the year 2242
+func isPlausibleID(id *ID) bool {
+	// Timestamps are little-endian (see mum), the upper bytes are zero for current times
+	return isUUIDv7(id) || (id[15] == 0 && id[14] == 0 && id[13] == 0 && id[12] < 2)
+}
+
+// parseAnyError will return the error of a failed ParseAny, ambiguous strings are
+// reported as such rather than as an invalid encoding
+func parseAnyError(err error) error {
+	if err == ErrAmbiguousEncoding {
+		return err
+	}
+
+	return ErrInvalidEncoding
+}
+
+// encode will return the string encoding of the provided bytes
+func encode(enc Encoding, src []byte) string {
+	// Large enough for a hex encoded ID
+	var buf [32]byte
+	return string(appendEncode(enc, buf[:0], src))
+}
+
+// appendEncode will append the encoding of the provided bytes to dst
+func appendEncode(enc Encoding, dst, src []byte) []byte {
+	n := enc.EncodedLen(len(src))
+	dst = slices.Grow(dst, n)
+	out := dst[len(dst) : len(dst)+n]
+	// Built-in encodings are called directly, this allows the compiler to
+	// prove the buffers do not escape which avoids allocations
+	switch e := enc.(type) {
+	case base64Encoding:
+		e.Encode(out, src)
+	case hexEncoding:
+		e.Encode(out, src)
+	case base32Encoding:
+		e.Encode(out, src)
+	case *baseNEncoding:
+		e.Encode(out, src)
+
+	default:
+		// Copies are passed to custom encodings so our buffers do not escape
+		tmp := make([]byte, n)
+		enc.Encode(tmp, slices.Clone(src))
+		copy(out, tmp)
+	}
+
+	return dst[:len(dst)+n]
+}
+
+// decode will decode src into dst after ensuring the length is valid
+func decode(enc Encoding, dst, src []byte) (err error) {
+	if len(src) != enc.EncodedLen(len(dst)) {
+		// Decoded value has to be the length of dst or it's not valid
+		return ErrInvalidLength
+	}
+
+	// Built-in encodings are called directly, see appendEncode
+	switch e := enc.(type) {
+	case base64Encoding:
+		return e.Decode(dst, src)
+	case hexEncoding:
+		return e.Decode(dst, src)
+	case base32Encoding:
+		return e.Decode(dst, src)
+	case *baseNEncoding:
+		return e.Decode(dst, src)
+
+	default:
+		// Copies are passed to custom encodings, see appendEncode
+		tmp := make([]byte, len(dst))
+		if err = enc.Decode(tmp, slices.Clone(src)); err != nil {
+			return
+		}
+
+		copy(dst, tmp)
+		return
+	}
+}
+
+// hexEncoding is the lowercase hexadecimal encoding
+type hexEncoding struct{}
+
+func (hexEncoding) Name() string         { return "hex" }
+func (hexEncoding) EncodedLen(n int) int { return hex.EncodedLen(n) }
+func (hexEncoding) Encode(dst, src []byte) {
+	hex.Encode(dst, src)
+}
+
+func (hexEncoding) Decode(dst, src []byte) (err error) {
+	_, err = hex.Decode(dst, src)
+	return
+}
+
+// base64Encoding wraps a standard library base64 encoding
+type base64Encoding struct {
+	name string
+	enc  *base64.Encoding
+}
+
+func (b base64Encoding) Name() string         { return b.name }
+func (b base64Encoding) EncodedLen(n int) int { return b.enc.EncodedLen(n) }
+func (b base64Encoding) Encode(dst, src []byte) {
+	b.enc.Encode(dst, src)
+}
+
+func (b base64Encoding) Decode(dst, src []byte) (err error) {
+	var n int
+	if n, err = b.enc.Decode(dst, src); err != nil {
+		return
+	}
+
+	// Newlines are ignored by the standard library, the ID must be entirely decoded
+	if n != len(dst) {
+		return ErrInvalidEncoding
+	}
+
+	return
+}
+
+// base32Encoding is Crockford's base32 encoding
+type base32Encoding struct {
+	enc *base32.Encoding
+}
+
+func (b base32Encoding) Name() string         { return "base32" }
+func (b base32Encoding) EncodedLen(n int) int { return b.enc.EncodedLen(n) }
+func (b base32Encoding) Encode(dst, src []byte) {
+	b.enc.Encode(dst, src)
+}
+
+func (b base32Encoding) Decode(dst, src []byte) (err error) {
+	// Large enough for an encoded ID
+	var buf [32]byte
+	if len(src) > len(buf) {
+		return ErrInvalidLength
+	}
+
+	norm := buf[:len(src)]
+	for i, c := range src {
+		// Normalize lowercase and ambiguous characters as described by Crockford
+		if c >= 'a' && c <= 'z' {
+			c -= 'a' - 'A'
+		}
+
+		switch c {
+		case 'O':
+			c = '0'
+		case 'I', 'L':
+			c = '1'
+		}
+
+		norm[i] = c
+	}
+
+	var n int
+	if n, err = b.enc.Decode(dst, norm); err != nil {
+		return
+	} else if n != len(dst) {
+		return ErrInvalidEncoding
+	}
+
+	// The standard library ignores trailing bits, re-encode to ensure they are zero so
+	// each ID has a single (case-insensitive) encoding
+	var cbuf [32]byte
+	canon := cbuf[:len(src)]
+	b.enc.Encode(canon, dst)
+	if string(canon) != string(norm) {
+		return ErrInvalidEncoding
+	}
+
+	return
+}
+
+// newBaseNEncoding will return a new fixed-width encoding for the provided alphabet
+func newBaseNEncoding(name, alphabet string) (b *baseNEncoding) {
+	var enc baseNEncoding
+	enc.name = name
+	enc.alphabet = alphabet
+	enc.bits = math.Log2(float64(len(alphabet)))
+	for i := range enc.vals {
+		enc.vals[i] = 0xff
+	}
+
+	for i := 0; i < len(alphabet); i++ {
+		enc.vals[alphabet[i]] = byte(i)
+	}
+
+	return &enc
+}
+
+// baseNEncoding is a fixed-width encoding for alphabets which are not a power of two
+type baseNEncoding struct {
+	name     string
+	alphabet string
+	// Bits per character
+	bits float64
+	// Character values, invalid characters are set to 0xff
+	vals [256]byte
+}
+
+func (b *baseNEncoding) Name() string { return b.name }
+func (b *baseNEncoding) EncodedLen(n int) int {
+	return int(math.Ceil(float64(n*8) / b.bits))
+}
+
+func (b *baseNEncoding) Encode(dst, src []byte) {
+	encodeBase(dst, src, b.alphabet)
+}
+
+func (b *baseNEncoding) Decode(dst, src []byte) (err error) {
+	// Large enough for an encoded ID
+	var buf [32]byte
+	if len(src) > len(buf) {
+		return ErrInvalidLength
+	}
+
+	digits := buf[:len(src)]
+	for i, c := range src {
+		if digits[i] = b.vals[c]; digits[i] == 0xff {
+			return ErrInvalidEncoding
+		}
+	}
+
+	if !decodeBase(dst, digits, uint(len(b.alphabet))) {
+		return ErrInvalidEncoding
+	}
+
+	return
+}
@@ -0,0 +1,158 @@
+package idg
+
+import "testing"
+
+func TestEncodings(t *testing.T) {
+	ids := []ID{{}, newID(1337, -1), newUUIDv7(1337, -1)}
+	for i := range ids {
+		ids[i][0] = 0xff
+	}
+
+	for _, enc := range Encodings {
+		for _, id := range ids {
+			str := id.Format(enc)
+			if len(str) != enc.EncodedLen(len(id)) {
+				t.Fatalf("invalid %s length, expected %d and received %d", enc.Name(), enc.EncodedLen(len(id)), len(str))
+			}
+
+			nid, err := ParseWith(enc, str)
+			if err != nil {
+				t.Fatalf("error parsing %s: %v", enc.Name(), err)
+			}
+
+			if nid != id {
+				t.Fatalf("%s ID's do not match: %v / %v", enc.Name(), id.Bytes(), nid.Bytes())
+			}
+		}
+
+		id32 := newID32(1337, -1)
+		nid32, err := ParseWith32(enc, id32.Format(enc))
+		if err != nil {
+			t.Fatalf("error parsing %s: %v", enc.Name(), err)
+		} else if nid32 != id32 {
+			t.Fatalf("%s ID's do not match: %v / %v", enc.Name(), id32.Bytes(), nid32.Bytes())
+		}
+	}
+
+	// Crockford's base32 should tolerate lowercase and ambiguous characters
+	var id ID
+	if nid, err := ParseWith(Base32, "oooooooooooooooooooooooooo"); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+}
+
+func TestParseAny(t *testing.T) {
+	id := newID(1337, -1)
+	strs := []string{id.Format(Hex), id.Format(Base32), id.String(), id.UUIDString(), id.HumanString()}
+	for _, str := range strs {
+		nid, err := ParseAny(str)
+		if err != nil {
+			t.Fatalf("error parsing %s: %v", str, err)
+		}
+
+		if nid != id {
+			t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+		}
+	}
+
+	if _, err := ParseAny("not an id"); err != ErrInvalidEncoding {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidEncoding, err)
+	}
+}
+
+func TestGeneratorEncoding(t *testing.T) {
+	idg := New(1337, WithEncoding(Base58))
+	id := idg.Next()
+	str := idg.Format(id)
+	if str != id.Format(Base58) {
+		t.Fatalf("invalid string, expected %s and received %s", id.Format(Base58), str)
+	}
+
+	nid, err := idg.Parse(str)
+	if err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+}
+
+func TestParseAnyEncodings(t *testing.T) {
+	defer SetEncoding(idEnc)
+	defer SetEncoding32(id32Enc)
+
+	var ids []ID
+	var ids32 []ID32
+	for i := uint64(0); i < 256; i++ {
+		ids = append(ids, newID(i*7919, int64(1700000000+i*104729)), newUUIDv7(i*7919, int64(1700000000000+i*104729)))
+		ids32 = append(ids32, newID32(uint32(i*7919), int64(1700000000+i*104729)))
+	}
+
+	for _, enc := range Encodings {
+		// Strings of the configured encoding are always parsed with the configured encoding
+		SetEncoding(enc)
+		SetEncoding32(enc)
+		for _, id := range ids {
+			if nid, err := ParseAny(id.Format(enc)); err != nil || nid != id {
+				t.Fatalf("invalid %s round trip, expected %v and received %v (%v)", enc.Name(), id.Bytes(), nid.Bytes(), err)
+			}
+		}
+
+		for _, id := range ids32 {
+			if nid, err := ParseAny32(id.Format(enc)); err != nil || nid != id {
+				t.Fatalf("invalid %s round trip, expected %v and received %v (%v)", enc.Name(), id.Bytes(), nid.Bytes(), err)
+			}
+		}
+	}
+
+	SetEncoding(Base64URL)
+	SetEncoding32(Base64URL)
+	for _, enc := range Encodings {
+		// Strings of other encodings are parsed or reported as ambiguous, never decoded as a different ID
+		for _, id := range ids {
+			str := id.Format(enc)
+			nid, err := ParseAny(str)
+			if err == ErrAmbiguousEncoding {
+				continue
+			}
+
+			if err != nil || nid != id {
+				t.Fatalf("invalid %s parse of %s, expected %v and received %v (%v)", enc.Name(), str, id.Bytes(), nid.Bytes(), err)
+			}
+		}
+
+		// ID32 has no structure to tell encodings apart, the configured encoding takes priority
+		for _, id := range ids32 {
+			str := id.Format(enc)
+			nid, err := ParseAny32(str)
+			if pid, perr := ParseWith32(Base64URL, str); err == ErrAmbiguousEncoding || (perr == nil && nid == pid) {
+				continue
+			}
+
+			if err != nil || nid != id {
+				t.Fatalf("invalid %s parse of %s, expected %v and received %v (%v)", enc.Name(), str, id.Bytes(), nid.Bytes(), err)
+			}
+		}
+	}
+}
+
+func TestStrictDecoding(t *testing.T) {
+	var id ID
+	// Canonical encodings end with zeroed trailing bits
+	strs := map[Encoding]string{
+		Base64URL: "AAAAAAAAAAAAAAAAAAAAAB",
+		Base32:    "00000000000000000000000001",
+	}
+
+	for enc, str := range strs {
+		if err := decode(enc, id[:], []byte(str)); err == nil {
+			t.Fatalf("expected %s trailing bits of %s to be rejected", enc.Name(), str)
+		}
+	}
+
+	// Newlines must not be ignored
+	if _, err := ParseWith(Base64URL, "AAAAAAAAAAAAAAAAAAAA\nA"); err == nil {
+		t.Fatal("expected newline to be rejected")
+	}
+}
@@ -0,0 +1,303 @@
+package idg
+
+import (
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/itsmontoya/mum"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrLocked is returned when a persistent file is locked by another process (or generator)
+	ErrLocked = errors.Error("file is locked by another process")
+	// ErrInvalidFile is returned when a persistent file is not of a known format
+	ErrInvalidFile = errors.Error("invalid persistent file")
+	// ErrFileChecksum is returned when the checksum of a persistent file does not match
+	ErrFileChecksum = errors.Error("persistent file checksum mismatch")
+	// ErrIndexBackwards is returned when an index would be moved backwards
+	ErrIndexBackwards = errors.Error("index cannot be moved backwards")
+)
+
+// FileExt is the file extension of persistent generator files
+const FileExt = ".idg"
+
+const (
+	// Magic bytes of the current file format, followed by the version byte
+	fileMagic   = "IDG"
+	fileVersion = 1
+	// Length of the current file format, magic (3), version (1), index (8) and checksum (4)
+	fileLen = 16
+	// Length of the legacy file format, a raw uint64
+	legacyFileLen = 8
+)
+
+// Castagnoli CRC table utilized for file checksums
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// FileFormat represents the format of a persistent file
+type FileFormat uint8
+
+const (
+	// FileEmpty is a file which has not yet had an index persisted
+	FileEmpty FileFormat = iota
+	// FileLegacy is the legacy format, a raw uint64 of the last issued index without a checksum
+	FileLegacy
+	// FileV1 is the current format, the next index with magic bytes and a CRC-32C checksum
+	FileV1
+)
+
+// String will return a string representation of a FileFormat
+func (f FileFormat) String() string {
+	switch f {
+	case FileEmpty:
+		return "empty"
+	case FileLegacy:
+		return "legacy"
+	case FileV1:
+		return "v1"
+
+	default:
+		return "invalid"
+	}
+}
+
+// OpenFile will open an existing persistent file and acquire its lock
+// Note: ErrLocked is returned when the file is locked, such as by a running PIDG
+func OpenFile(fp string) (f *File, err error) {
+	return openFile(fp, 0)
+}
+
+// RepairFile will open an existing persistent file and acquire its lock, a file which fails
+// its checksum is opened regardless and rewritten with the index it holds. repaired is false
+// when the checksum of the file matches
+// Note: The index of a repaired file may be corrupt, verify it (e.g. against a snapshot or
+// journal) and Bump the file as needed before it is utilized by a generator
+func RepairFile(fp string) (f *File, repaired bool, err error) {
+	if f, err = openLocked(fp, 0); err != nil {
+		return
+	}
+
+	if err = f.read(); err == ErrFileChecksum {
+		if err = f.write(f.idx); err == nil {
+			err = f.f.Sync()
+		}
+
+		repaired = err == nil
+	}
+
+	if err != nil {
+		f.Close()
+		f = nil
+	}
+
+	return
+}
+
+// openFile will open a persistent file with the provided additional flags and acquire its lock
+func openFile(fp string, flag int) (f *File, err error) {
+	if f, err = openLocked(fp, flag); err != nil {
+		return
+	}
+
+	if err = f.read(); err != nil {
+		f.Close()
+		f = nil
+	}
+
+	return
+}
+
+// openLocked will open a persistent file with the provided additional flags and acquire its
+// lock without reading it
+func openLocked(fp string, flag int) (f *File, err error) {
+	var file File
+	if file.f, err = os.OpenFile(fp, os.O_RDWR|flag, 0644); err != nil {
+		return
+	}
+
+	if err = lockFile(file.f); err != nil {
+		file.f.Close()
+		return
+	}
+
+	f = &file
+	return
+}
+
+// File is a locked persistent generator file
+type File struct {
+	f *os.File
+	// Format of the file when it was read
+	format FileFormat
+	// Next index to be issued
+	idx uint64
+	// Write buffer
+	buf [fileLen]byte
+}
+
+// read will read and verify the contents of the file
+func (f *File) read() (err error) {
+	var br mum.BinaryReader
+	buf := make([]byte, fileLen+1)
+	var n int
+	if n, err = io.ReadFull(f.f, buf); err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return
+	}
+
+	err = nil
+	switch {
+	case n == 0:
+		f.format = FileEmpty
+		return
+	case n == legacyFileLen:
+		var last uint64
+		if last, err = br.Uint64(buf[:legacyFileLen]); err != nil {
+			return
+		}
+
+		// Legacy files hold the last issued index
+		f.format = FileLegacy
+		f.idx = last + 1
+		return
+	case n == fileLen && string(buf[:3]) == fileMagic && buf[3] == fileVersion:
+		f.format = FileV1
+		if f.idx, err = br.Uint64(buf[4:12]); err != nil {
+			return
+		}
+
+		// The index is read regardless so the file can be repaired, see RepairFile
+		if crc32.Checksum(buf[:12], crcTable) != binary.BigEndian.Uint32(buf[12:16]) {
+			return ErrFileChecksum
+		}
+
+		return
+
+	default:
+		return ErrInvalidFile
+	}
+}
+
+// write will persist the provided next index utilizing the format of the file, legacy files
+// remain legacy until they are migrated so older releases can still read them
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (f *File) write(idx uint64) (err error) {
+	// A legacy file cannot hold a next index of zero (there is no last issued index), the
+	// file is written in the current format instead
+	if f.format != FileLegacy || idx == 0 {
+		return f.writeV1(idx)
+	}
+
+	var bw mum.BinaryWriter
+	// Legacy files hold the last issued index
+	copy(f.buf[:legacyFileLen], bw.Uint64(idx-1))
+	if _, err = f.f.WriteAt(f.buf[:legacyFileLen], 0); err != nil {
+		return
+	}
+
+	f.idx = idx
+	return
+}
+
+// writeV1 will persist the provided next index utilizing the current format
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (f *File) writeV1(idx uint64) (err error) {
+	var bw mum.BinaryWriter
+	copy(f.buf[:3], fileMagic)
+	f.buf[3] = fileVersion
+	copy(f.buf[4:12], bw.Uint64(idx))
+	binary.BigEndian.PutUint32(f.buf[12:16], crc32.Checksum(f.buf[:12], crcTable))
+
+	// The record is written with a single write so a torn write is detected by the checksum
+	if _, err = f.f.WriteAt(f.buf[:], 0); err != nil {
+		return
+	}
+
+	f.format = FileV1
+	f.idx = idx
+	return
+}
+
+// Name will return the name of the file
+func (f *File) Name() string {
+	return f.f.Name()
+}
+
+// Key will return the generator key of the file
+func (f *File) Key() string {
+	return strings.TrimSuffix(filepath.Base(f.f.Name()), FileExt)
+}
+
+// Format will return the format of the file
+func (f *File) Format() FileFormat {
+	return f.format
+}
+
+// Index will return the next index to be issued
+func (f *File) Index() uint64 {
+	return f.idx
+}
+
+// Bump will move the next index to be issued forward to idx
+// Note: ErrIndexBackwards is returned when idx is less than the current index
+func (f *File) Bump(idx uint64) (err error) {
+	if idx < f.idx {
+		return ErrIndexBackwards
+	}
+
+	if err = f.write(idx); err != nil {
+		return
+	}
+
+	return f.f.Sync()
+}
+
+// Migrate will migrate a legacy file to the current format, migrated is false when the
+// file is already of the current format
+func (f *File) Migrate() (migrated bool, err error) {
+	if f.format != FileLegacy {
+		return
+	}
+
+	if err = f.writeV1(f.idx); err != nil {
+		return
+	}
+
+	if err = f.f.Sync(); err != nil {
+		return
+	}
+
+	migrated = true
+	return
+}
+
+// Close will release the lock and close the file
+func (f *File) Close() (err error) {
+	return f.f.Close()
+}
+
+// ListFiles will return the sorted paths of the persistent files within a directory
+func ListFiles(dir string) (fps []string, err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(dir); err != nil {
+		return
+	}
+
+	for _, e := range entries {
+		if e.IsDir() || filepath.Ext(e.Name()) != FileExt {
+			continue
+		}
+
+		fps = append(fps, filepath.Join(dir, e.Name()))
+	}
+
+	sort.Strings(fps)
+	return
+}
@@ -0,0 +1,271 @@
+package idg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/itsmontoya/mum"
+)
+
+func TestFileLegacy(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	if err := os.MkdirAll("./test_data", 0744); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write a legacy file with a last issued index of 41
+	var bw mum.BinaryWriter
+	fp := filepath.Join("./test_data", "legacy"+FileExt)
+	if err := os.WriteFile(fp, bw.Uint64(41), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPersistent("legacy", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var id ID
+	if id, err = p.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 42); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Persisting does not migrate the file, it holds the last issued index of 42
+	var b []byte
+	if b, err = os.ReadFile(fp); err != nil || len(b) != legacyFileLen {
+		t.Fatalf("invalid legacy file: %v (%v)", b, err)
+	}
+
+	var br mum.BinaryReader
+	if last, _ := br.Uint64(b); last != 42 {
+		t.Fatalf("invalid last issued index, expected %d and received %d", 42, last)
+	}
+
+	var f *File
+	if f, err = OpenFile(fp); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Format() != FileLegacy || f.Index() != 43 || f.Key() != "legacy" {
+		t.Fatalf("invalid file, format %v, index %d and key %s", f.Format(), f.Index(), f.Key())
+	}
+
+	var migrated bool
+	if migrated, err = f.Migrate(); err != nil || !migrated {
+		t.Fatalf("invalid migration, migrated %v and error %v", migrated, err)
+	}
+
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err = NewPersistent("legacy", "./test_data"); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if p.f.Format() != FileV1 {
+		t.Fatalf("invalid format, expected %v and received %v", FileV1, p.f.Format())
+	}
+
+	if id, err = p.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 43); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileLock(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	p, err := NewPersistent("locked", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fp := filepath.Join("./test_data", "locked"+FileExt)
+	if _, err = OpenFile(fp); err != ErrLocked {
+		t.Fatalf("invalid error, expected %v and received %v", ErrLocked, err)
+	}
+
+	if _, err = NewPersistent("locked", "./test_data"); err != ErrLocked {
+		t.Fatalf("invalid error, expected %v and received %v", ErrLocked, err)
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var f *File
+	if f, err = OpenFile(fp); err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	if f.Format() != FileEmpty || f.Index() != 0 {
+		t.Fatalf("invalid file, format %v and index %d", f.Format(), f.Index())
+	}
+}
+
+func TestFileBump(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	p, err := NewPersistent("bump", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = p.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := filepath.Join("./test_data", "bump"+FileExt)
+	var f *File
+	if f, err = OpenFile(fp); err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Format() != FileV1 || f.Index() != 3 {
+		t.Fatalf("invalid file, format %v and index %d", f.Format(), f.Index())
+	}
+
+	if err = f.Bump(2); err != ErrIndexBackwards {
+		t.Fatalf("invalid error, expected %v and received %v", ErrIndexBackwards, err)
+	}
+
+	if err = f.Bump(1000); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err = NewPersistent("bump", "./test_data"); err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	var id ID
+	if id, err = p.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 1000); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFileCorrupt(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	p, err := NewPersistent("corrupt", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = p.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	fp := filepath.Join("./test_data", "corrupt"+FileExt)
+	var b []byte
+	if b, err = os.ReadFile(fp); err != nil {
+		t.Fatal(err)
+	}
+
+	// Flip a bit of the index
+	b[5] ^= 1
+	if err = os.WriteFile(fp, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = OpenFile(fp); err != ErrFileChecksum {
+		t.Fatalf("invalid error, expected %v and received %v", ErrFileChecksum, err)
+	}
+
+	// Repairing rewrites the file with the index it holds, bit 8 of the index was flipped
+	var (
+		f        *File
+		repaired bool
+	)
+
+	if f, repaired, err = RepairFile(fp); err != nil || !repaired || f.Index() != 1<<8|1 {
+		t.Fatalf("invalid repair, repaired %v and error %v", repaired, err)
+	}
+
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if f, repaired, err = RepairFile(fp); err != nil || repaired {
+		t.Fatalf("invalid second repair, repaired %v and error %v", repaired, err)
+	}
+
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err = NewPersistent("corrupt", "./test_data"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.WriteFile(fp, b[:12], 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = NewPersistent("corrupt", "./test_data"); err != ErrInvalidFile {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidFile, err)
+	}
+
+	// Files of an unknown format cannot be repaired
+	if _, _, err = RepairFile(fp); err != ErrInvalidFile {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidFile, err)
+	}
+}
+
+func TestListFiles(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	for _, key := range []string{"b", "a"} {
+		p, err := NewPersistent(key, "./test_data")
+		if err != nil {
+			t.Fatal(err)
+		}
+		p.Close()
+	}
+
+	if err := os.WriteFile(filepath.Join("./test_data", "other.txt"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fps, err := ListFiles("./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(fps) != 2 || filepath.Base(fps[0]) != "a.idg" || filepath.Base(fps[1]) != "b.idg" {
+		t.Fatalf("invalid files: %v", fps)
+	}
+}
@@ -0,0 +1,104 @@
+package idg
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Hooks receives generator lifecycle events, embed NopHooks to implement a subset of the events
+// Note: Implementations must be safe for concurrent use and should not block
+type Hooks interface {
+	// OnOpen is called when a generator has been opened, idx is the next index to be issued
+	// (the recovered index for PIDG, zero for TIDG, SIDG and LIDG whose index is not known
+	// until the first ID)
+	OnOpen(key string, idx uint64)
+	// OnPersistError is called when persisting the next index, idx, fails
+	OnPersistError(key string, idx uint64, err error)
+	// OnClose is called when a generator has been closed, err is set when closing failed
+	OnClose(key string, err error)
+	// OnOverflow is called when an index (or Snowflake time) overflows the space of an ID
+	OnOverflow(key string, idx uint64)
+}
+
+// NopHooks is a no-op implementation of Hooks
+type NopHooks struct{}
+
+// OnOpen is a no-op
+func (NopHooks) OnOpen(key string, idx uint64) {}
+
+// OnPersistError is a no-op
+func (NopHooks) OnPersistError(key string, idx uint64, err error) {}
+
+// OnClose is a no-op
+func (NopHooks) OnClose(key string, err error) {}
+
+// OnOverflow is a no-op
+func (NopHooks) OnOverflow(key string, idx uint64) {}
+
+// WithHooks will set the lifecycle hooks of a generator
+func WithHooks(h Hooks) Option {
+	return func(o *opts) {
+		o.hooks = h
+	}
+}
+
+// WithLogger will set the structured logger of a generator, lifecycle events are logged
+// at the info level, overflows and clock regressions at the warn level and failures at
+// the error level
+// Note: Generators do not log unless a logger is set
+func WithLogger(l *slog.Logger) Option {
+	return func(o *opts) {
+		o.logger = l
+	}
+}
+
+// log will log a message with the key of the generator
+func (o *opts) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if o.logger == nil {
+		return
+	}
+
+	attrs = append(attrs, slog.String("key", o.key))
+	o.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
+
+// opened will report an opened generator with the provided next index
+func (o *opts) opened(idx uint64, attrs ...slog.Attr) {
+	if o.hooks != nil {
+		o.hooks.OnOpen(o.key, idx)
+	}
+
+	o.log(slog.LevelInfo, "idg: generator opened", append(attrs, slog.Uint64("index", idx))...)
+}
+
+// persistFailed will report a failed persist of the next index
+func (o *opts) persistFailed(idx uint64, err error) {
+	if o.hooks != nil {
+		o.hooks.OnPersistError(o.key, idx, err)
+	}
+
+	o.log(slog.LevelError, "idg: error persisting index", slog.Uint64("index", idx), slog.Any("error", err))
+}
+
+// closed will report a closed generator
+func (o *opts) closed(err error) {
+	if o.hooks != nil {
+		o.hooks.OnClose(o.key, err)
+	}
+
+	if err != nil {
+		o.log(slog.LevelError, "idg: error closing generator", slog.Any("error", err))
+		return
+	}
+
+	o.log(slog.LevelInfo, "idg: generator closed")
+}
+
+// overflowed will report an overflowed index
+func (o *opts) overflowed(idx uint64) {
+	if o.hooks != nil {
+		o.hooks.OnOverflow(o.key, idx)
+	}
+
+	o.log(slog.LevelWarn, "idg: index overflow", slog.Uint64("index", idx))
+}
@@ -0,0 +1,166 @@
+package idg
+
+import (
+	"bytes"
+	"log/slog"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PathDNA/turtleDB"
+)
+
+func TestHooks(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	var (
+		h   = &testHooks{}
+		buf bytes.Buffer
+	)
+
+	logger := slog.New(slog.NewTextHandler(&buf, nil))
+	p, err := NewPersistent("hooks", "./test_data", WithHooks(h), WithLogger(logger))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = p.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if p, err = NewPersistent("hooks", "./test_data", WithHooks(h), WithLogger(logger)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Inject a read-only handle of the file to force a persist error
+	rw := p.f.f
+	if p.f.f, err = os.Open(rw.Name()); err != nil {
+		t.Fatal(err)
+	}
+	defer rw.Close()
+
+	if _, err = p.Next(); err == nil {
+		t.Fatal("expected persist error")
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Closing a closed generator fails
+	if err = p.Close(); err == nil {
+		t.Fatal("expected close error")
+	}
+
+	expected := []string{
+		"open hooks 0",
+		"close hooks <nil>",
+		"open hooks 3",
+		"persist hooks 4",
+		"close hooks <nil>",
+		"close hooks error",
+	}
+
+	if got := strings.Join(h.events, ","); got != strings.Join(expected, ",") {
+		t.Fatalf("invalid events, expected %v and received %v", expected, h.events)
+	}
+
+	for _, msg := range []string{"generator opened", "error persisting index", "error closing generator", "key=hooks", "format=v1"} {
+		if !strings.Contains(buf.String(), msg) {
+			t.Fatalf("log does not contain %q:\n%s", msg, buf.String())
+		}
+	}
+}
+
+func TestHooksOverflow(t *testing.T) {
+	h := &testHooks{}
+	gen := New(math.MaxUint32+1, WithHooks(h), WithKey("overflow"))
+	gen.Next32()
+
+	gen = New(math.MaxUint64, WithHooks(h), WithKey("overflow"))
+	gen.Next()
+
+	expected := "open overflow 4294967296,overflow overflow 4294967296,open overflow 18446744073709551615,overflow overflow 18446744073709551615"
+	if got := strings.Join(h.events, ","); got != expected {
+		t.Fatalf("invalid events, expected %s and received %s", expected, got)
+	}
+}
+
+func TestHooksGenerators(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	h := &testHooks{}
+	fm := turtleDB.FuncsMap{}
+	tidg := NewTIDG("tidg", fm, WithHooks(h))
+	if err := tidg.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewSnowflake(1, TwitterSnowflake, WithHooks(h), WithKey("sidg")); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := NewPersistent("source", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var l *LIDG
+	if l, err = NewLeased(p, 10, WithHooks(h), WithKey("lidg")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "open tidg 0,close tidg <nil>,open sidg 0,open lidg 0,close lidg <nil>"
+	if got := strings.Join(h.events, ","); got != expected {
+		t.Fatalf("invalid events, expected %s and received %s", expected, got)
+	}
+}
+
+type testHooks struct {
+	NopHooks
+
+	mux    sync.Mutex
+	events []string
+}
+
+func (h *testHooks) add(event string) {
+	h.mux.Lock()
+	defer h.mux.Unlock()
+	h.events = append(h.events, event)
+}
+
+func (h *testHooks) OnOpen(key string, idx uint64) {
+	h.add("open " + key + " " + uitoa(idx))
+}
+
+func (h *testHooks) OnPersistError(key string, idx uint64, err error) {
+	h.add("persist " + key + " " + uitoa(idx))
+}
+
+func (h *testHooks) OnClose(key string, err error) {
+	if err != nil {
+		h.add("close " + key + " error")
+		return
+	}
+
+	h.add("close " + key + " <nil>")
+}
+
+func (h *testHooks) OnOverflow(key string, idx uint64) {
+	h.add("overflow " + key + " " + uitoa(idx))
+}
+
+func uitoa(v uint64) string {
+	return strconv.FormatUint(v, 10)
+}
@@ -0,0 +1,223 @@
+package idg
+
+import (
+	"strconv"
+
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidCharacter is returned when a human-friendly string contains a character outside of the alphabet
+	ErrInvalidCharacter = errors.Error("invalid character")
+	// ErrInvalidChecksum is returned when the check characters of a human-friendly string do not match
+	ErrInvalidChecksum = errors.Error("invalid checksum")
+)
+
+const (
+	// Alphabet of human-friendly strings. Ambiguous characters (0, O, 1, I and L) are excluded
+	humanAlphabet = "23456789ABCDEFGHJKMNPQRSTUVWXYZ"
+	// Base of human-friendly strings, this is prime which allows locating single errors
+	humanBase = len(humanAlphabet)
+	// Number of check characters
+	humanCheckLen = 2
+	// Number of characters per group
+	humanGroupLen = 4
+	// Separator between groups
+	humanSep = '-'
+
+	// Number of data characters of an ID (31^26 > 2^128)
+	humanDataLen = 26
+	// Number of data characters of an ID32 (31^13 > 2^64)
+	humanDataLen32 = 13
+)
+
+// humanValues is a lookup table of character values, invalid characters are set to 0xff
+var humanValues = func() (vals [256]byte) {
+	for i := range vals {
+		vals[i] = 0xff
+	}
+
+	for i := 0; i < humanBase; i++ {
+		c := humanAlphabet[i]
+		vals[c] = byte(i)
+		// Lowercase characters are accepted as well
+		if c >= 'A' && c <= 'Z' {
+			vals[c+'a'-'A'] = byte(i)
+		}
+	}
+
+	return
+}()
+
+// HumanError is returned when a human-friendly string cannot be parsed
+type HumanError struct {
+	// Position of the likely error within the input string, -1 if it cannot be determined
+	Pos int
+	// Underlying error
+	Err error
+}
+
+// Error will return the error message
+func (e *HumanError) Error() string {
+	if e.Pos == -1 {
+		return e.Err.Error()
+	}
+
+	return e.Err.Error() + " at position " + strconv.Itoa(e.Pos)
+}
+
+// Unwrap will return the underlying error
+func (e *HumanError) Unwrap() error {
+	return e.Err
+}
+
+// HumanString will return a human-friendly string representation with check characters
+// The string is case-insensitive, does not contain ambiguous characters and is split into
+// groups of four characters for dictation (e.g. 2222-2222-2222-2222-2222-2222-2222)
+func (id *ID) HumanString() (out string) {
+	if id == nil {
+		return
+	}
+
+	return encodeHuman(id[:], humanDataLen)
+}
+
+// HumanString will return a human-friendly string representation with check characters
+func (id *ID32) HumanString() (out string) {
+	if id == nil {
+		return
+	}
+
+	return encodeHuman(id[:], humanDataLen32)
+}
+
+// ParseHuman will parse a human-friendly string id
+// Note: Errors are of type *HumanError, which reports the position of the likely error
+func ParseHuman(in string) (id ID, err error) {
+	err = decodeHuman(id[:], in, humanDataLen)
+	return
+}
+
+// ParseHuman32 will parse a human-friendly 32-bit string id
+// Note: Errors are of type *HumanError, which reports the position of the likely error
+func ParseHuman32(in string) (id ID32, err error) {
+	err = decodeHuman(id[:], in, humanDataLen32)
+	return
+}
+
+// encodeHuman will return the human-friendly representation of the provided bytes
+func encodeHuman(src []byte, dataLen int) string {
+	var (
+		chars [humanDataLen + humanCheckLen]byte
+		vals  [humanDataLen + humanCheckLen]byte
+	)
+
+	n := dataLen + humanCheckLen
+	encodeBase(chars[:dataLen], src, humanAlphabet)
+	for i, c := range chars[:dataLen] {
+		vals[i] = humanValues[c]
+	}
+
+	// Set check characters so both syndromes are zero
+	x, y := humanCheck(vals[:dataLen])
+	chars[dataLen] = humanAlphabet[x]
+	chars[dataLen+1] = humanAlphabet[y]
+
+	out := make([]byte, 0, n+n/humanGroupLen)
+	for i, c := range chars[:n] {
+		if i > 0 && i%humanGroupLen == 0 {
+			out = append(out, humanSep)
+		}
+
+		out = append(out, c)
+	}
+
+	return string(out)
+}
+
+// decodeHuman will decode the provided human-friendly string into dst
+func decodeHuman(dst []byte, in string, dataLen int) (err error) {
+	var (
+		vals [humanDataLen + humanCheckLen]byte
+		// Position of each value within the input string
+		pos [humanDataLen + humanCheckLen]int
+		n   int
+	)
+
+	for i := 0; i < len(in); i++ {
+		c := in[i]
+		if c == humanSep || c == ' ' {
+			continue
+		}
+
+		if n == dataLen+humanCheckLen {
+			return &HumanError{Pos: i, Err: ErrInvalidLength}
+		}
+
+		if vals[n] = humanValues[c]; vals[n] == 0xff {
+			return &HumanError{Pos: i, Err: ErrInvalidCharacter}
+		}
+
+		pos[n] = i
+		n++
+	}
+
+	if n != dataLen+humanCheckLen {
+		return &HumanError{Pos: -1, Err: ErrInvalidLength}
+	}
+
+	if s1, s2 := humanSyndromes(vals[:n]); s1 != 0 || s2 != 0 {
+		herr := &HumanError{Pos: -1, Err: ErrInvalidChecksum}
+		// A single substituted character at position p results in s2 == (p + 1) * s1
+		if s1 != 0 {
+			if p := s2*inverse(s1)%humanBase - 1; p >= 0 && p < n {
+				herr.Pos = pos[p]
+			}
+		}
+
+		return herr
+	}
+
+	if !decodeBase(dst, vals[:dataLen], uint(humanBase)) {
+		return &HumanError{Pos: -1, Err: ErrInvalidLength}
+	}
+
+	return
+}
+
+// humanCheck will return the check values for the provided data values. The check values
+// are chosen so the sum and the position-weighted sum of all values are zero (mod 31)
+func humanCheck(data []byte) (x, y byte) {
+	s1, s2 := humanSyndromes(data)
+	// Weights of the check values are a and a + 1
+	a := len(data) + 1
+	// Solve x + y = -s1 and a*x + (a+1)*y = -s2
+	yv := (a*s1 - s2) % humanBase
+	if yv < 0 {
+		yv += humanBase
+	}
+
+	xv := (2*humanBase - s1 - yv) % humanBase
+	return byte(xv), byte(yv)
+}
+
+// humanSyndromes will return the sum and position-weighted sum of the provided values (mod 31)
+func humanSyndromes(vals []byte) (s1, s2 int) {
+	for i, v := range vals {
+		s1 += int(v)
+		s2 += (i + 1) * int(v)
+	}
+
+	return s1 % humanBase, s2 % humanBase
+}
+
+// inverse will return the multiplicative inverse of v (mod 31)
+func inverse(v int) int {
+	// Fermat's little theorem, v^(p-2) is the inverse of v (mod p)
+	out := 1
+	for i := 0; i < humanBase-2; i++ {
+		out = out * v % humanBase
+	}
+
+	return out
+}
@@ -0,0 +1,93 @@
+package idg
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestHuman(t *testing.T) {
+	var (
+		nid ID
+		err error
+	)
+
+	var empty ID
+	if str := empty.HumanString(); str != "2222-2222-2222-2222-2222-2222-2222" {
+		t.Fatalf("invalid human string, expected %s and received %s", "2222-2222-2222-2222-2222-2222-2222", str)
+	}
+
+	id := newID(1337, -1)
+	str := id.HumanString()
+	if strings.ContainsAny(str, "0O1lI") {
+		t.Fatalf("human string contains ambiguous characters: %s", str)
+	}
+
+	if nid, err = ParseHuman(str); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	// Lowercase and missing separators should be tolerated
+	if nid, err = ParseHuman(strings.ToLower(strings.Replace(str, "-", "", -1))); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	id32 := newID32(1337, -1)
+	if id32, err = ParseHuman32(id32.HumanString()); err != nil {
+		t.Fatal(err)
+	} else if err = testIndex32(id32, 1337); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHumanTypo(t *testing.T) {
+	id := newID(1337, -1)
+	str := id.HumanString()
+	// Substitute every non-separator character and ensure the position is reported
+	for i := 0; i < len(str); i++ {
+		if str[i] == humanSep {
+			continue
+		}
+
+		b := []byte(str)
+		if b[i] == 'Z' {
+			b[i] = '2'
+		} else {
+			b[i] = humanAlphabet[strings.IndexByte(humanAlphabet, b[i])+1]
+		}
+
+		var herr *HumanError
+		if _, err := ParseHuman(string(b)); !errors.As(err, &herr) {
+			t.Fatalf("invalid error, expected *HumanError and received %v", err)
+		} else if herr.Err != ErrInvalidChecksum || herr.Pos != i {
+			t.Fatalf("invalid error, expected %v at %d and received %v", ErrInvalidChecksum, i, err)
+		}
+	}
+
+	// Ambiguous characters should be reported at their position
+	b := []byte(str)
+	b[5] = 'O'
+	var herr *HumanError
+	if _, err := ParseHuman(string(b)); !errors.As(err, &herr) {
+		t.Fatalf("invalid error, expected *HumanError and received %v", err)
+	} else if herr.Err != ErrInvalidCharacter || herr.Pos != 5 {
+		t.Fatalf("invalid error, expected %v at %d and received %v", ErrInvalidCharacter, 5, err)
+	}
+
+	// Swapped adjacent characters should be detected
+	for i := 0; i < len(str)-1; i++ {
+		if str[i] == str[i+1] || str[i] == humanSep || str[i+1] == humanSep {
+			continue
+		}
+
+		b = []byte(str)
+		b[i], b[i+1] = b[i+1], b[i]
+		if _, err := ParseHuman(string(b)); err == nil {
+			t.Fatalf("expected transposition at %d to be detected", i)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package idg
 
 import (
-	"encoding/json"
 	"time"
 
 	"github.com/itsmontoya/mum"
@@ -34,20 +33,24 @@ func newID(idx uint64, ts int64) (id ID) {
 	return
 }
 
+// Compose will return an ID with the provided index, time and layout
+// Note: Index-first IDs store the time in seconds and UUIDv7 IDs store the time in milliseconds.
+// Index-first IDs of indexes which generators skip (see isAmbiguousIndex) may be read as UUIDv7 IDs
+func Compose(idx uint64, t time.Time, l Layout) (id ID) {
+	if l == LayoutUUIDv7 {
+		return newUUIDv7(idx, t.UnixMilli())
+	}
+
+	return newID(idx, t.Unix())
+}
+
 // ID represents an id
 type ID [16]byte
 
 func (id *ID) parse(in []byte) (err error) {
-	if len(in) != strLen {
-		// Decoded value has to be 16 bytes or it's not valid
-		err = ErrInvalidLength
-		return
-	}
-
-	// Decode inbound bytes as base64
+	// Decode inbound bytes utilizing the configured encoding
 	// Write the bytes directly to our array
-	_, err = b64.Decode(id[:], in)
-	return
+	return decode(idEnc, id[:], in)
 }
 
 // Index will return the index of an ID
@@ -60,6 +63,11 @@ func (id *ID) Index() (idx uint64, err error) {
 		err = ErrEmptyID
 		return
 	}
+	// Check if ID is utilizing the UUIDv7 layout
+	if isUUIDv7(id) {
+		idx = uuidV7Index(id)
+		return
+	}
 	// Grab the index from the first 8 bytes
 	return br.Uint64(id[:8])
 }
@@ -78,6 +86,11 @@ func (id *ID) Time() (t time.Time, err error) {
 		err = ErrEmptyID
 		return
 	}
+	// Check if ID is utilizing the UUIDv7 layout
+	if isUUIDv7(id) {
+		t = uuidV7Time(id)
+		return
+	}
 	// Grab the Unix timestamp from the last 8 bytes
 	if ts, err = br.Int64(id[8:]); err != nil {
 		return
@@ -108,7 +121,40 @@ func (id *ID) String() (out string) {
 		return
 	}
 
-	out = b64.EncodeToString(id[:])
+	out = encode(idEnc, id[:])
+	return
+}
+
+// AppendText will append the string representation to dst
+// Note: No allocations are made when dst has enough capacity
+func (id *ID) AppendText(dst []byte) (out []byte, err error) {
+	if id == nil {
+		return dst, nil
+	}
+
+	return appendEncode(idEnc, dst, id[:]), nil
+}
+
+// AppendJSON will append the JSON representation to dst
+// Note: No allocations are made when dst has enough capacity. Empty IDs are appended as null
+func (id *ID) AppendJSON(dst []byte) (out []byte, err error) {
+	if id.IsEmpty() {
+		return append(dst, jsonNull...), nil
+	}
+
+	out = append(dst, '"')
+	out = appendEncode(idEnc, out, id[:])
+	out = append(out, '"')
+	return
+}
+
+// Format will return a string representation utilizing the provided encoding
+func (id *ID) Format(enc Encoding) (out string) {
+	if id == nil {
+		return
+	}
+
+	out = encode(enc, id[:])
 	return
 }
 
@@ -118,24 +164,40 @@ func (id *ID) IsEmpty() (empty bool) {
 }
 
 // MarshalJSON is a JSON encoding helper func
+// Note: Empty IDs are encoded as null
 func (id *ID) MarshalJSON() (out []byte, err error) {
-	// Check if ID is nil
-	if id == nil {
-		return
+	if id.IsEmpty() {
+		return id.AppendJSON(nil)
 	}
 
-	return json.Marshal(id.String())
+	// Allocate enough capacity for the encoded ID and quotation marks
+	return id.AppendJSON(make([]byte, 0, idEnc.EncodedLen(len(id))+2))
 }
 
 // UnmarshalJSON is a JSON decoding helper func
+// Note: null is decoded as an empty ID. Strings of any built-in encoding are accepted,
+// see ParseAny. Errors are of type *JSONError
 func (id *ID) UnmarshalJSON(in []byte) (err error) {
-	var str string
-	// Unmarshal inbound value as a string
-	if err = json.Unmarshal(in, &str); err != nil {
+	if isJSONNull(in) {
+		*id = emptyID
 		return
 	}
-	// Strip double-quotation from head and tail
-	stripped := in[1 : len(in)-1]
-	// Return result of the parsed value
-	return id.parse(stripped)
+
+	var str []byte
+	// Unmarshal inbound value as a string
+	if str, err = jsonString(in); err != nil {
+		return newJSONError("ID", in, err)
+	}
+
+	var nid ID
+	// Attempt the configured encoding before detecting the encoding, the ID is
+	// only assigned once decoding has succeeded
+	if err = nid.parse(str); err != nil {
+		if nid, err = ParseAny(string(str)); err != nil {
+			return newJSONError("ID", in, err)
+		}
+	}
+
+	*id = nid
+	return
 }
@@ -1,7 +1,8 @@
 package idg
 
 import (
-	"encoding/json"
+	"bytes"
+	"strconv"
 	"time"
 
 	"github.com/itsmontoya/mum"
@@ -30,20 +31,18 @@ func newID32(idx uint32, ts int64) (id ID32) {
 	return
 }
 
+// Compose32 will return an ID32 with the provided index and time (in seconds)
+func Compose32(idx uint32, t time.Time) (id ID32) {
+	return newID32(idx, t.Unix())
+}
+
 // ID32 represents a 32-bit id
 type ID32 [8]byte
 
 func (id *ID32) parse(in []byte) (err error) {
-	if len(in) != strLen {
-		// Decoded value has to be 16 bytes or it's not valid
-		err = ErrInvalidLength
-		return
-	}
-
-	// Decode inbound bytes as base64
+	// Decode inbound bytes utilizing the configured encoding
 	// Write the bytes directly to our array
-	_, err = b64.Decode(id[:], in)
-	return
+	return decode(id32Enc, id[:], in)
 }
 
 // Index will return the index of an ID
@@ -104,7 +103,50 @@ func (id *ID32) String() (out string) {
 		return
 	}
 
-	out = b64.EncodeToString(id[:])
+	out = encode(id32Enc, id[:])
+	return
+}
+
+// AppendText will append the string representation to dst
+// Note: No allocations are made when dst has enough capacity
+func (id *ID32) AppendText(dst []byte) (out []byte, err error) {
+	if id == nil {
+		return dst, nil
+	}
+
+	return appendEncode(id32Enc, dst, id[:]), nil
+}
+
+// AppendJSON will append the JSON representation to dst
+// Note: No allocations are made when dst has enough capacity. Empty IDs are appended
+// as null and IDs are appended as decimal strings when enabled with SetJSONNumber32
+func (id *ID32) AppendJSON(dst []byte) (out []byte, err error) {
+	if id.IsEmpty() {
+		return append(dst, jsonNull...), nil
+	}
+
+	if jsonNumber32 {
+		// The numeric representation exceeds 2^53, it is quoted so decoders utilizing
+		// float64 numbers (e.g. JavaScript) do not lose precision
+		out = append(dst, '"')
+		out = strconv.AppendUint(out, id.Uint64(), 10)
+		out = append(out, '"')
+		return
+	}
+
+	out = append(dst, '"')
+	out = appendEncode(id32Enc, out, id[:])
+	out = append(out, '"')
+	return
+}
+
+// Format will return a string representation utilizing the provided encoding
+func (id *ID32) Format(enc Encoding) (out string) {
+	if id == nil {
+		return
+	}
+
+	out = encode(enc, id[:])
 	return
 }
 
@@ -113,25 +155,82 @@ func (id *ID32) IsEmpty() (empty bool) {
 	return id == nil || *id == emptyID32
 }
 
-// MarshalJSON is a JSON encoding helper func
-func (id *ID32) MarshalJSON() (out []byte, err error) {
-	// Check if ID is nil
+// Uint64 will return the numeric representation of an ID32
+// Note: The timestamp is held within the upper 32 bits and the index within the
+// lower 32 bits, so numeric ordering follows time then index
+func (id *ID32) Uint64() (v uint64) {
 	if id == nil {
 		return
 	}
 
-	return json.Marshal(id.String())
+	var br mum.BinaryReader
+	idx, _ := br.Uint32(id[:4])
+	ts, _ := br.Uint32(id[4:])
+	return uint64(ts)<<32 | uint64(idx)
+}
+
+// ID32FromUint64 will return the ID32 of the provided numeric representation
+func ID32FromUint64(v uint64) (id ID32) {
+	return newID32(uint32(v), int64(v>>32))
+}
+
+// MarshalJSON is a JSON encoding helper func
+// Note: Empty IDs are encoded as null
+func (id *ID32) MarshalJSON() (out []byte, err error) {
+	if id.IsEmpty() {
+		return id.AppendJSON(nil)
+	}
+
+	// Allocate enough capacity for the encoded ID and quotation marks
+	return id.AppendJSON(make([]byte, 0, id32Enc.EncodedLen(len(id))+2))
 }
 
 // UnmarshalJSON is a JSON decoding helper func
+// Note: null is decoded as an empty ID. Numbers, decimal strings and strings of any
+// built-in encoding are accepted, see ParseAny32. Errors are of type *JSONError
 func (id *ID32) UnmarshalJSON(in []byte) (err error) {
-	var str string
+	if isJSONNull(in) {
+		*id = emptyID32
+		return
+	}
+
+	if trimmed := bytes.TrimSpace(in); len(trimmed) > 0 && trimmed[0] >= '0' && trimmed[0] <= '9' {
+		var v uint64
+		// Unmarshal inbound value as a number
+		if v, err = strconv.ParseUint(string(trimmed), 10, 64); err != nil {
+			return newJSONError("ID32", in, err)
+		}
+
+		*id = ID32FromUint64(v)
+		return
+	}
+
+	var str []byte
 	// Unmarshal inbound value as a string
-	if err = json.Unmarshal(in, &str); err != nil {
+	if str, err = jsonString(in); err != nil {
+		return newJSONError("ID32", in, err)
+	}
+
+	var nid ID32
+	if isDecimal32(str) {
+		var v uint64
+		// Unmarshal inbound value as a decimal string
+		if v, err = strconv.ParseUint(string(str), 10, 64); err != nil {
+			return newJSONError("ID32", in, err)
+		}
+
+		*id = ID32FromUint64(v)
 		return
 	}
-	// Strip double-quotation from head and tail
-	stripped := in[1 : len(in)-1]
-	// Return result of the parsed value
-	return id.parse(stripped)
+
+	// Attempt the configured encoding before detecting the encoding, the ID is
+	// only assigned once decoding has succeeded
+	if err = nid.parse(str); err != nil {
+		if nid, err = ParseAny32(string(str)); err != nil {
+			return newJSONError("ID32", in, err)
+		}
+	}
+
+	*id = nid
+	return
 }
@@ -0,0 +1,99 @@
+package idg
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"strconv"
+
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidID64 is returned when a value cannot be parsed as an ID64
+	ErrInvalidID64 = errors.Error("invalid ID64")
+)
+
+// ID64 represents a Snowflake-compatible 64-bit id
+// Note: The bit layout of an ID64 is described by a Snowflake
+type ID64 int64
+
+// ParseID64 will parse a decimal string id
+func ParseID64(in string) (id ID64, err error) {
+	var v int64
+	if v, err = strconv.ParseInt(in, 10, 64); err != nil {
+		err = ErrInvalidID64
+		return
+	}
+
+	id = ID64(v)
+	return
+}
+
+// Int64 will return the int64 representation of an ID64
+func (id ID64) Int64() int64 {
+	return int64(id)
+}
+
+// String will return the decimal string representation of an ID64
+func (id ID64) String() string {
+	return strconv.FormatInt(int64(id), 10)
+}
+
+// IsEmpty will return if an ID64 is empty
+func (id ID64) IsEmpty() (empty bool) {
+	return id == 0
+}
+
+// MarshalJSON is a JSON encoding helper func
+// Note: ID64 values are encoded as JSON numbers
+func (id ID64) MarshalJSON() (out []byte, err error) {
+	return strconv.AppendInt(nil, int64(id), 10), nil
+}
+
+// UnmarshalJSON is a JSON decoding helper func
+// Note: Both JSON numbers and decimal strings are accepted. Strings are supported
+// for Javascript clients which cannot represent 64-bit integers. null is decoded
+// as an empty ID64. Errors are of type *JSONError
+func (id *ID64) UnmarshalJSON(in []byte) (err error) {
+	if isJSONNull(in) {
+		*id = 0
+		return
+	}
+
+	if trimmed := bytes.TrimSpace(in); len(trimmed) > 0 && trimmed[0] == '"' {
+		var str []byte
+		if str, err = jsonString(trimmed); err != nil {
+			return newJSONError("ID64", in, err)
+		}
+
+		in = str
+	}
+
+	if *id, err = ParseID64(string(bytes.TrimSpace(in))); err != nil {
+		return newJSONError("ID64", in, err)
+	}
+
+	return
+}
+
+// Value will return the int64 value of an ID64 for database/sql
+func (id ID64) Value() (v driver.Value, err error) {
+	return int64(id), nil
+}
+
+// Scan will scan a database/sql value into an ID64
+func (id *ID64) Scan(src interface{}) (err error) {
+	switch v := src.(type) {
+	case int64:
+		*id = ID64(v)
+	case []byte:
+		*id, err = ParseID64(string(v))
+	case string:
+		*id, err = ParseID64(v)
+
+	default:
+		err = ErrInvalidID64
+	}
+
+	return
+}
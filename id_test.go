@@ -48,6 +48,38 @@ func TestIDParse(t *testing.T) {
 	}
 }
 
+func TestCompose(t *testing.T) {
+	ts := time.Unix(1700000000, 123e6)
+	for _, l := range []Layout{LayoutIndexFirst, LayoutUUIDv7} {
+		id := Compose(1337, ts, l)
+		if id.Layout() != l {
+			t.Fatalf("invalid layout, expected %v and received %v", l, id.Layout())
+		}
+
+		if err := testIndex(id, 1337); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := ts.Truncate(time.Second)
+		if l == LayoutUUIDv7 {
+			expected = ts.Truncate(time.Millisecond)
+		}
+
+		if tt, _ := id.Time(); !tt.Equal(expected) {
+			t.Fatalf("invalid time, expected %v and received %v", expected, tt)
+		}
+	}
+
+	id32 := Compose32(7, ts)
+	if err := testIndex32(id32, 7); err != nil {
+		t.Fatal(err)
+	}
+
+	if tt, _ := id32.Time(); !tt.Equal(ts.Truncate(time.Second)) {
+		t.Fatalf("invalid time, expected %v and received %v", ts.Truncate(time.Second), tt)
+	}
+}
+
 func TestJSON(t *testing.T) {
 	var (
 		b   []byte
@@ -101,3 +133,86 @@ func TestJSONStruct(t *testing.T) {
 type testStruct struct {
 	ID *ID `json:"id"`
 }
+
+func TestIDAppend(t *testing.T) {
+	var (
+		b   []byte
+		err error
+	)
+
+	id := newID(1337, -1)
+	if b, err = id.AppendText([]byte("id=")); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "id="+id.String() {
+		t.Fatalf("invalid text, expected %s and received %s", "id="+id.String(), b)
+	}
+
+	if b, err = id.AppendJSON(nil); err != nil {
+		t.Fatal(err)
+	} else if string(b) != `"`+id.String()+`"` {
+		t.Fatalf("invalid JSON, expected %s and received %s", `"`+id.String()+`"`, b)
+	}
+}
+
+func TestIDZeroAlloc(t *testing.T) {
+	var err error
+	id := newID(1337, -1)
+	buf := make([]byte, 0, 64)
+	str := []byte(id.String())
+	allocs := testing.AllocsPerRun(100, func() {
+		if buf, err = id.AppendText(buf[:0]); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf, err = id.AppendJSON(buf[:0]); err != nil {
+			t.Fatal(err)
+		}
+
+		if idSink, err = ParseBytes(str); err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	if allocs != 0 {
+		t.Fatalf("invalid number of allocations, expected 0 and received %v", allocs)
+	}
+}
+
+func BenchmarkID_AppendText(b *testing.B) {
+	id := newID(1337, -1)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ = id.AppendText(buf[:0])
+	}
+}
+
+func BenchmarkID_AppendJSON(b *testing.B) {
+	id := newID(1337, -1)
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf, _ = id.AppendJSON(buf[:0])
+	}
+}
+
+func BenchmarkID_ParseBytes(b *testing.B) {
+	id := newID(1337, -1)
+	str := []byte(id.String())
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		idSink, _ = ParseBytes(str)
+	}
+}
+
+func BenchmarkID_MarshalJSON(b *testing.B) {
+	id := newID(1337, -1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		jsonSink, _ = id.MarshalJSON()
+	}
+}
@@ -16,21 +16,32 @@ const (
 var (
 	// Base64 RawURLEncoding alias
 	b64 = base64.RawURLEncoding
-	// String length
-	strLen = b64.EncodedLen(16)
 	// Empty ID used for matching
 	emptyID   = ID{}
 	emptyID32 = ID32{}
 )
 
+// Generator is implemented by ID generators which can fail, such as PIDG or
+// remote generators (e.g. the idgd client)
+type Generator interface {
+	// Next will return the next id
+	Next() (ID, error)
+	// Next32 will return the next 32-bit id
+	Next32() (ID32, error)
+}
+
 // New will return a new ID generator
-func New(idx uint64) (idg IDG) {
+func New(idx uint64, ops ...Option) (idg IDG) {
+	idg.opts.apply(ops)
 	idg.idx.Store(idx)
+	idg.opened(idx)
 	return
 }
 
 // IDG is an non-persistent atomic ID generator
 type IDG struct {
+	opts
+
 	mux atoms.Mux
 	// Helper for binary encoding
 	bw mum.BinaryWriter
@@ -43,7 +54,13 @@ func (i *IDG) Next() (id ID) {
 	// We atomically increment our current index by one.
 	// It is safe to assume that our index is one less than the new value
 	idx := i.idx.Add(1) - 1
-	return newID(idx, -1)
+	for i.layout == LayoutIndexFirst && isAmbiguousIndex(idx) {
+		// Move past indexes which may be mistaken for UUIDv7 IDs, concurrent callers
+		// may move further which only skips additional indexes
+		idx = i.idx.Add(ambiguousEnd(idx)-idx) - 1
+	}
+
+	return i.newID(idx)
 }
 
 // Next32 will return the next 32-bit id
@@ -51,5 +68,5 @@ func (i *IDG) Next32() (id ID32) {
 	// We atomically increment our current index by one.
 	// It is safe to assume that our index is one less than the new value
 	idx := i.idx.Add(1) - 1
-	return newID32(uint32(idx), -1)
+	return i.newID32(idx)
 }
@@ -9,6 +9,7 @@ import (
 
 var (
 	idSink   ID
+	jsonSink []byte
 	uuidSink uuid.UUID
 )
 
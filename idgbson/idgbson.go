@@ -0,0 +1,132 @@
+// Package idgbson provides BSON codecs for idg.ID and idg.ID32
+package idgbson
+
+import (
+	"reflect"
+
+	"github.com/PathDNA/idg"
+	"github.com/missionMeteora/toolkit/errors"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+)
+
+const (
+	// ErrInvalidBSON is returned when a BSON value cannot be decoded as an ID
+	ErrInvalidBSON = errors.Error("invalid BSON value, expected binary or null")
+)
+
+const (
+	// SubtypeGeneric is the BSON binary subtype of IDs
+	SubtypeGeneric byte = 0x00
+	// SubtypeUUID is the BSON binary subtype of UUIDs, utilized for IDs with idg.LayoutUUIDv7
+	SubtypeUUID byte = 0x04
+)
+
+var (
+	typeID   = reflect.TypeOf(idg.ID{})
+	typeID32 = reflect.TypeOf(idg.ID32{})
+)
+
+// Register will register the ID and ID32 codecs with the provided registry
+// Note: IDs are encoded as binary, IDs with idg.LayoutUUIDv7 utilize the UUID subtype. null is
+// decoded as an empty ID. Pointers to IDs are handled by the default pointer codec
+//
+// Usage:
+//
+//	reg := bson.NewRegistry()
+//	idgbson.Register(reg)
+//	client, err := mongo.Connect(ctx, options.Client().SetRegistry(reg))
+func Register(reg *bsoncodec.Registry) {
+	reg.RegisterTypeEncoder(typeID, bsoncodec.ValueEncoderFunc(encodeID))
+	reg.RegisterTypeDecoder(typeID, bsoncodec.ValueDecoderFunc(decodeID))
+	reg.RegisterTypeEncoder(typeID32, bsoncodec.ValueEncoderFunc(encodeID32))
+	reg.RegisterTypeDecoder(typeID32, bsoncodec.ValueDecoderFunc(decodeID32))
+}
+
+// NewRegistry will return a new default registry with the ID and ID32 codecs registered
+func NewRegistry() (reg *bsoncodec.Registry) {
+	reg = bson.NewRegistry()
+	Register(reg)
+	return
+}
+
+// encodeID will encode an ID as binary
+func encodeID(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) (err error) {
+	if !val.IsValid() || val.Type() != typeID {
+		return bsoncodec.ValueEncoderError{Name: "encodeID", Types: []reflect.Type{typeID}, Received: val}
+	}
+
+	id := val.Interface().(idg.ID)
+	subtype := SubtypeGeneric
+	if id.Layout() == idg.LayoutUUIDv7 {
+		subtype = SubtypeUUID
+	}
+
+	return vw.WriteBinaryWithSubtype(id[:], subtype)
+}
+
+// decodeID will decode a binary or null value as an ID
+func decodeID(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) (err error) {
+	if !val.CanSet() || val.Type() != typeID {
+		return bsoncodec.ValueDecoderError{Name: "decodeID", Types: []reflect.Type{typeID}, Received: val}
+	}
+
+	var id idg.ID
+	if err = decode(vr, id[:]); err != nil {
+		return
+	}
+
+	val.Set(reflect.ValueOf(id))
+	return
+}
+
+// encodeID32 will encode an ID32 as binary
+func encodeID32(_ bsoncodec.EncodeContext, vw bsonrw.ValueWriter, val reflect.Value) (err error) {
+	if !val.IsValid() || val.Type() != typeID32 {
+		return bsoncodec.ValueEncoderError{Name: "encodeID32", Types: []reflect.Type{typeID32}, Received: val}
+	}
+
+	id := val.Interface().(idg.ID32)
+	return vw.WriteBinaryWithSubtype(id[:], SubtypeGeneric)
+}
+
+// decodeID32 will decode a binary or null value as an ID32
+func decodeID32(_ bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) (err error) {
+	if !val.CanSet() || val.Type() != typeID32 {
+		return bsoncodec.ValueDecoderError{Name: "decodeID32", Types: []reflect.Type{typeID32}, Received: val}
+	}
+
+	var id idg.ID32
+	if err = decode(vr, id[:]); err != nil {
+		return
+	}
+
+	val.Set(reflect.ValueOf(id))
+	return
+}
+
+// decode will decode a binary value into dst, null leaves dst empty
+func decode(vr bsonrw.ValueReader, dst []byte) (err error) {
+	switch vr.Type() {
+	case bsontype.Null:
+		return vr.ReadNull()
+	case bsontype.Binary:
+
+	default:
+		return ErrInvalidBSON
+	}
+
+	var b []byte
+	if b, _, err = vr.ReadBinary(); err != nil {
+		return
+	}
+
+	if len(b) != len(dst) {
+		return idg.ErrInvalidLength
+	}
+
+	copy(dst, b)
+	return
+}
@@ -0,0 +1,63 @@
+package idgbson
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PathDNA/idg"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+type testStruct struct {
+	ID   idg.ID   `bson:"id"`
+	ID32 idg.ID32 `bson:"id32"`
+	Ptr  *idg.ID  `bson:"ptr"`
+	Nil  *idg.ID  `bson:"nil"`
+}
+
+func TestBSON(t *testing.T) {
+	now := time.Now()
+	v7 := idg.Compose(1337, now, idg.LayoutUUIDv7)
+	ts := testStruct{
+		ID:   idg.Compose(1337, now, idg.LayoutIndexFirst),
+		ID32: idg.Compose32(1337, now),
+		Ptr:  &v7,
+	}
+
+	reg := NewRegistry()
+	b, err := bson.MarshalWithRegistry(reg, ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	raw := bson.Raw(b)
+	if st, bin := raw.Lookup("id").Binary(); st != SubtypeGeneric || len(bin) != 16 {
+		t.Fatalf("invalid id value, subtype %d and length %d", st, len(bin))
+	}
+
+	if st, bin := raw.Lookup("id32").Binary(); st != SubtypeGeneric || len(bin) != 8 {
+		t.Fatalf("invalid id32 value, subtype %d and length %d", st, len(bin))
+	}
+
+	if st, _ := raw.Lookup("ptr").Binary(); st != SubtypeUUID {
+		t.Fatalf("invalid subtype, expected %d and received %d", SubtypeUUID, st)
+	}
+
+	var nts testStruct
+	if err = bson.UnmarshalWithRegistry(reg, b, &nts); err != nil {
+		t.Fatal(err)
+	}
+
+	if nts.ID != ts.ID || nts.ID32 != ts.ID32 || nts.Ptr == nil || *nts.Ptr != v7 || nts.Nil != nil {
+		t.Fatalf("values do not match: %v / %v", ts, nts)
+	}
+
+	// Non-binary values are rejected
+	if b, err = bson.Marshal(bson.M{"id": "string"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = bson.UnmarshalWithRegistry(reg, b, &nts); err == nil {
+		t.Fatal("expected error decoding a string as an ID")
+	}
+}
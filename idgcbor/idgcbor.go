@@ -0,0 +1,126 @@
+// Package idgcbor provides CBOR tagged representations of idg.ID and idg.ID32
+package idgcbor
+
+import (
+	"github.com/PathDNA/idg"
+	"github.com/fxamacker/cbor/v2"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidCBOR is returned when a CBOR value cannot be decoded as an ID
+	ErrInvalidCBOR = errors.Error("invalid CBOR value, expected tagged byte string")
+)
+
+const (
+	// TagID is the CBOR tag of ID
+	// Note: This is within the first come first served range and is not registered
+	TagID uint64 = 0x69640010
+	// TagID32 is the CBOR tag of ID32
+	// Note: This is within the first come first served range and is not registered
+	TagID32 uint64 = 0x69640008
+	// TagUUID is the registered CBOR tag of binary UUIDs, utilized for IDs with idg.LayoutUUIDv7
+	TagUUID uint64 = 37
+)
+
+// ID is an idg.ID which is encoded as a tagged CBOR byte string, convert with ID(id) and idg.ID(id)
+type ID idg.ID
+
+// MarshalCBOR is a CBOR encoding helper func
+// Note: IDs are encoded as tagged byte strings, IDs with idg.LayoutUUIDv7 utilize the UUID tag.
+// A nil ID is encoded as null
+func (id *ID) MarshalCBOR() (out []byte, err error) {
+	if id == nil {
+		return cbor.Marshal(nil)
+	}
+
+	tag := TagID
+	if (*idg.ID)(id).Layout() == idg.LayoutUUIDv7 {
+		tag = TagUUID
+	}
+
+	return cbor.Marshal(cbor.Tag{Number: tag, Content: id[:]})
+}
+
+// UnmarshalCBOR is a CBOR decoding helper func
+// Note: null and untagged byte strings are accepted
+func (id *ID) UnmarshalCBOR(in []byte) (err error) {
+	if id == nil {
+		return idg.ErrEmptyID
+	}
+
+	return unmarshal(id[:], in, TagID, TagUUID)
+}
+
+// ID32 is an idg.ID32 which is encoded as a tagged CBOR byte string, convert with ID32(id) and idg.ID32(id)
+type ID32 idg.ID32
+
+// MarshalCBOR is a CBOR encoding helper func
+// Note: IDs are encoded as tagged byte strings. A nil ID is encoded as null
+func (id *ID32) MarshalCBOR() (out []byte, err error) {
+	if id == nil {
+		return cbor.Marshal(nil)
+	}
+
+	return cbor.Marshal(cbor.Tag{Number: TagID32, Content: id[:]})
+}
+
+// UnmarshalCBOR is a CBOR decoding helper func
+// Note: null and untagged byte strings are accepted
+func (id *ID32) UnmarshalCBOR(in []byte) (err error) {
+	if id == nil {
+		return idg.ErrEmptyID
+	}
+
+	return unmarshal(id[:], in, TagID32)
+}
+
+// unmarshal will decode a CBOR byte string with one of the provided tags into dst
+func unmarshal(dst, in []byte, tags ...uint64) (err error) {
+	var v interface{}
+	if err = cbor.Unmarshal(in, &v); err != nil {
+		return
+	}
+
+	var bs []byte
+	switch val := v.(type) {
+	case nil:
+		for i := range dst {
+			dst[i] = 0
+		}
+
+		return
+	case []byte:
+		bs = val
+	case cbor.Tag:
+		if !hasTag(tags, val.Number) {
+			return ErrInvalidCBOR
+		}
+
+		var ok bool
+		if bs, ok = val.Content.([]byte); !ok {
+			return ErrInvalidCBOR
+		}
+
+	default:
+		return ErrInvalidCBOR
+	}
+
+	if len(bs) != len(dst) {
+		return idg.ErrInvalidLength
+	}
+
+	copy(dst, bs)
+	return
+}
+
+// hasTag will return whether or not the provided tag is within tags
+func hasTag(tags []uint64, tag uint64) bool {
+	for _, t := range tags {
+		if t == tag {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,55 @@
+package idgcbor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PathDNA/idg"
+	"github.com/fxamacker/cbor/v2"
+)
+
+type testStruct struct {
+	ID   ID   `cbor:"id"`
+	ID32 ID32 `cbor:"id32"`
+	Ptr  *ID  `cbor:"ptr"`
+	Nil  *ID  `cbor:"nil"`
+}
+
+func TestCBOR(t *testing.T) {
+	now := time.Now()
+	v7 := ID(idg.Compose(1337, now, idg.LayoutUUIDv7))
+	ts := testStruct{
+		ID:   ID(idg.Compose(1337, now, idg.LayoutIndexFirst)),
+		ID32: ID32(idg.Compose32(1337, now)),
+		Ptr:  &v7,
+	}
+
+	b, err := cbor.Marshal(&ts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var nts testStruct
+	if err = cbor.Unmarshal(b, &nts); err != nil {
+		t.Fatal(err)
+	}
+
+	if nts.ID != ts.ID || nts.ID32 != ts.ID32 || nts.Ptr == nil || *nts.Ptr != v7 || nts.Nil != nil {
+		t.Fatalf("values do not match: %v / %v", ts, nts)
+	}
+
+	var tag cbor.RawTag
+	if b, err = cbor.Marshal(ts.Ptr); err != nil {
+		t.Fatal(err)
+	} else if err = cbor.Unmarshal(b, &tag); err != nil {
+		t.Fatal(err)
+	} else if tag.Number != TagUUID {
+		t.Fatalf("invalid tag, expected %d and received %d", TagUUID, tag.Number)
+	}
+
+	// A nil receiver is encoded as null rather than panicking
+	var nilID *ID
+	if b, err = nilID.MarshalCBOR(); err != nil || len(b) != 1 || b[0] != 0xf6 {
+		t.Fatalf("invalid nil encoding: %x (%v)", b, err)
+	}
+}
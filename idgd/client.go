@@ -0,0 +1,107 @@
+package idgd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/PathDNA/idg"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+// Ensure Client implements idg.Generator
+var _ idg.Generator = &Client{}
+
+// NewClient will return a new client for the provided idgd base URL (e.g. http://localhost:8080) and key
+// Note: If hc is nil, http.DefaultClient will be utilized
+func NewClient(baseURL, key string, hc *http.Client) (c *Client, err error) {
+	if !idg.IsValidKey(key) {
+		err = ErrInvalidKey
+		return
+	}
+
+	if hc == nil {
+		hc = http.DefaultClient
+	}
+
+	var cc Client
+	cc.hc = hc
+	cc.url = baseURL + "/keys/" + url.PathEscape(key) + "/next"
+	c = &cc
+	return
+}
+
+// Client is an idgd client which implements the same interface as the local generators
+type Client struct {
+	hc *http.Client
+	// URL of the next endpoint for the client key
+	url string
+}
+
+// Next will return the next id
+func (c *Client) Next() (id idg.ID, err error) {
+	var ids []idg.ID
+	if ids, err = c.NextN(1); err != nil {
+		return
+	}
+
+	id = ids[0]
+	return
+}
+
+// Next32 will return the next 32-bit id
+func (c *Client) Next32() (id idg.ID32, err error) {
+	var resp Response
+	if resp, err = c.request(1, "32"); err != nil {
+		return
+	}
+
+	if len(resp.IDs32) != 1 {
+		err = errors.Error("invalid response, expected 1 ID and received " + strconv.Itoa(len(resp.IDs32)))
+		return
+	}
+
+	id = resp.IDs32[0]
+	return
+}
+
+// NextN will return the next n ids
+func (c *Client) NextN(n int) (ids []idg.ID, err error) {
+	var resp Response
+	if resp, err = c.request(n, "128"); err != nil {
+		return
+	}
+
+	if len(resp.IDs) != n {
+		err = errors.Error("invalid response, expected " + strconv.Itoa(n) + " IDs and received " + strconv.Itoa(len(resp.IDs)))
+		return
+	}
+
+	ids = resp.IDs
+	return
+}
+
+// request will request the next n ids of the provided width
+func (c *Client) request(n int, width string) (resp Response, err error) {
+	var res *http.Response
+	if res, err = c.hc.Post(c.url+"?count="+strconv.Itoa(n)+"&width="+width, "", nil); err != nil {
+		return
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		var eresp Response
+		// Error responses of idgd hold the error message, any other response (e.g. a proxy) does not
+		if derr := json.NewDecoder(res.Body).Decode(&eresp); derr != nil || eresp.Error == "" {
+			err = errors.Error("unexpected response status: " + res.Status)
+			return
+		}
+
+		err = errors.Error(eresp.Error)
+		return
+	}
+
+	err = json.NewDecoder(res.Body).Decode(&resp)
+	return
+}
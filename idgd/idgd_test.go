@@ -0,0 +1,267 @@
+package idgd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PathDNA/idg"
+)
+
+func newTestServer(t *testing.T) (s *Server, ts *httptest.Server) {
+	dir, err := os.MkdirTemp("", "idgd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	s = NewServer(PersistentOpener(dir))
+	ts = httptest.NewServer(s)
+	t.Cleanup(ts.Close)
+	return
+}
+
+func TestClient(t *testing.T) {
+	s, ts := newTestServer(t)
+	c, err := NewClient(ts.URL, "orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id, err := c.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idx, _ := id.Index(); idx != 0 {
+		t.Fatalf("invalid index, expected %d and received %d", 0, idx)
+	}
+
+	ids, err := c.NextN(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, id := range ids {
+		if idx, _ := id.Index(); idx != uint64(i+1) {
+			t.Fatalf("invalid index, expected %d and received %d", i+1, idx)
+		}
+	}
+
+	id32, err := c.Next32()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if idx, _ := id32.Index(); idx != 11 {
+		t.Fatalf("invalid index, expected %d and received %d", 11, idx)
+	}
+
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Next(); err == nil || err.Error() != ErrClosed.Error() {
+		t.Fatalf("invalid error, expected %v and received %v", ErrClosed, err)
+	}
+}
+
+func TestClientConcurrent(t *testing.T) {
+	s, ts := newTestServer(t)
+	defer s.Close()
+
+	var (
+		wg   sync.WaitGroup
+		mux  sync.Mutex
+		seen = make(map[idg.ID]struct{})
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c, err := NewClient(ts.URL, "concurrent", nil)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			ids, err := c.NextN(50)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+
+			mux.Lock()
+			defer mux.Unlock()
+			for _, id := range ids {
+				seen[id] = struct{}{}
+			}
+		}()
+	}
+
+	wg.Wait()
+	if len(seen) != 400 {
+		t.Fatalf("invalid number of unique IDs, expected %d and received %d", 400, len(seen))
+	}
+}
+
+func TestServerInvalid(t *testing.T) {
+	s, ts := newTestServer(t)
+	defer s.Close()
+
+	tcs := []struct {
+		path   string
+		status int
+	}{
+		{"/keys/..%2Fescape/next", http.StatusBadRequest},
+		{"/keys/orders/next?count=0", http.StatusBadRequest},
+		{"/keys/orders/next?count=1001", http.StatusBadRequest},
+		{"/keys/orders/next?count=abc", http.StatusBadRequest},
+		{"/keys/orders/next?width=64", http.StatusBadRequest},
+	}
+
+	for _, tc := range tcs {
+		res, err := http.Post(ts.URL+tc.path, "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+
+		if res.StatusCode != tc.status {
+			t.Fatalf("invalid status for %s, expected %d and received %d", tc.path, tc.status, res.StatusCode)
+		}
+	}
+
+	if _, err := NewClient(ts.URL, "a/b", nil); err != ErrInvalidKey {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidKey, err)
+	}
+}
+
+func TestClientStatus(t *testing.T) {
+	// A non-idgd error response (e.g. a proxy) should report the status
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "bad gateway", http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	c, err := NewClient(ts.URL, "orders", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.Next(); err == nil || !strings.Contains(err.Error(), "502") {
+		t.Fatalf("invalid error, expected the response status and received %v", err)
+	}
+}
+
+func TestServerReserve(t *testing.T) {
+	dir, err := os.MkdirTemp("", "idgd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var src *countingSource
+	s := NewServer(func(key string) (Source, error) {
+		p, err := idg.NewPersistent(key, dir)
+		src = &countingSource{PIDG: p}
+		return src, err
+	})
+	defer s.Close()
+
+	ts := httptest.NewServer(s)
+	defer ts.Close()
+
+	var c *Client
+	if c, err = NewClient(ts.URL, "orders", nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = c.NextN(100); err != nil {
+		t.Fatal(err)
+	}
+
+	// The block of a request is reserved (and persisted) once
+	if src.reserves != 1 {
+		t.Fatalf("invalid number of reservations, expected %d and received %d", 1, src.reserves)
+	}
+}
+
+type countingSource struct {
+	*idg.PIDG
+	reserves int
+}
+
+func (c *countingSource) Reserve(n uint64) (start uint64, err error) {
+	c.reserves++
+	return c.PIDG.Reserve(n)
+}
+
+func TestGeneratorOpening(t *testing.T) {
+	dir, err := os.MkdirTemp("", "idgd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	var (
+		mux     sync.Mutex
+		opens   = make(map[string]int)
+		entered = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	open := PersistentOpener(dir)
+	s := NewServer(func(key string) (Source, error) {
+		mux.Lock()
+		opens[key]++
+		mux.Unlock()
+
+		if key == "slow" {
+			close(entered)
+			<-release
+		}
+
+		return open(key)
+	})
+	defer s.Close()
+
+	var (
+		wg   sync.WaitGroup
+		gens [4]Source
+	)
+
+	for i := range gens {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			var gerr error
+			if gens[i], gerr = s.generator("slow"); gerr != nil {
+				t.Error(gerr)
+			}
+		}(i)
+	}
+
+	<-entered
+	// Generators are opened outside of the server lock, other keys are not blocked
+	if _, err = s.generator("fast"); err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if opens["slow"] != 1 || opens["fast"] != 1 {
+		t.Fatalf("invalid number of opens: %v", opens)
+	}
+
+	for i, g := range gens {
+		if g != gens[0] {
+			t.Fatalf("invalid generator #%d, expected the generator of the pending open", i)
+		}
+	}
+}
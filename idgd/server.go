@@ -0,0 +1,272 @@
+// Package idgd is an HTTP ID issuing service and client
+package idgd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/PathDNA/atoms"
+	"github.com/PathDNA/idg"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidKey is returned when a key is not valid
+	ErrInvalidKey = errors.Error("invalid key, keys must be 1 to 64 alphanumeric, dash or underscore characters")
+	// ErrInvalidCount is returned when a count is not valid
+	ErrInvalidCount = errors.Error("invalid count")
+	// ErrInvalidWidth is returned when a width is not valid
+	ErrInvalidWidth = errors.Error("invalid width, expected 32 or 128")
+	// ErrClosed is returned when an action is performed on a closed server
+	ErrClosed = errors.Error("server is closed")
+)
+
+const (
+	// MaxCount is the maximum number of IDs issued by a single request
+	MaxCount = 1000
+)
+
+// Source is implemented by generators which reserve contiguous blocks of indexes, such as idg.PIDG
+type Source interface {
+	idg.BlockSource
+	// IDOf will return the ID of a reserved index
+	IDOf(idx uint64) idg.ID
+	// ID32Of will return the 32-bit ID of a reserved index
+	ID32Of(idx uint64) idg.ID32
+}
+
+// Opener will open the generator for the provided key
+// Note: Generators which implement io.Closer are closed when the server is closed
+type Opener func(key string) (Source, error)
+
+// PersistentOpener will return an Opener which opens persistent generators within the provided directory
+func PersistentOpener(dir string, ops ...idg.Option) Opener {
+	return func(key string) (g Source, err error) {
+		return idg.NewPersistent(key, dir, ops...)
+	}
+}
+
+// NewServer will return a new server which issues IDs from the generators of the provided opener
+func NewServer(open Opener) *Server {
+	var s Server
+	s.open = open
+	s.gens = make(map[string]Source)
+	s.opening = make(map[string]*opening)
+	s.mux = http.NewServeMux()
+	s.mux.HandleFunc("POST /keys/{key}/next", s.handleNext)
+	return &s
+}
+
+// Server is an HTTP ID issuing service
+type Server struct {
+	mux *http.ServeMux
+
+	gmux atoms.Mux
+	// Generator opener
+	open Opener
+	// Generators by key
+	gens map[string]Source
+	// Generators being opened by key
+	opening map[string]*opening
+	// Closed state
+	closed bool
+}
+
+// ServeHTTP will serve an HTTP request
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+// handleNext will handle POST /keys/{key}/next?count=N&width=32|128
+func (s *Server) handleNext(w http.ResponseWriter, r *http.Request) {
+	var (
+		resp Response
+		g    Source
+		err  error
+	)
+
+	key := r.PathValue("key")
+	count := 1
+	if str := r.URL.Query().Get("count"); str != "" {
+		if count, err = strconv.Atoi(str); err != nil || count < 1 || count > MaxCount {
+			writeError(w, http.StatusBadRequest, ErrInvalidCount)
+			return
+		}
+	}
+
+	width := r.URL.Query().Get("width")
+	if width != "" && width != "32" && width != "128" {
+		writeError(w, http.StatusBadRequest, ErrInvalidWidth)
+		return
+	}
+
+	if g, err = s.generator(key); err != nil {
+		switch err {
+		case ErrInvalidKey:
+			writeError(w, http.StatusBadRequest, err)
+		case ErrClosed:
+			writeError(w, http.StatusServiceUnavailable, err)
+
+		default:
+			writeError(w, http.StatusInternalServerError, err)
+		}
+
+		return
+	}
+
+	var start uint64
+	// Reserve (and persist) the block of the request once, the IDs are built from the block
+	if start, err = g.Reserve(uint64(count)); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if width == "32" {
+		resp.IDs32 = make([]idg.ID32, count)
+		for i := range resp.IDs32 {
+			resp.IDs32[i] = g.ID32Of(start + uint64(i))
+		}
+	} else {
+		resp.IDs = make([]idg.ID, count)
+		for i := range resp.IDs {
+			resp.IDs[i] = g.IDOf(start + uint64(i))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(&resp)
+}
+
+// generator will return the generator for the provided key, opening it if needed
+// Note: Generators are opened outside of the server lock, concurrent requests for a key
+// which is being opened wait for the pending open
+func (s *Server) generator(key string) (g Source, err error) {
+	if !idg.IsValidKey(key) {
+		err = ErrInvalidKey
+		return
+	}
+
+	var ok bool
+	s.gmux.Read(func() {
+		if s.closed {
+			err = ErrClosed
+			return
+		}
+
+		g, ok = s.gens[key]
+	})
+
+	if ok || err != nil {
+		return
+	}
+
+	var (
+		o     *opening
+		owner bool
+	)
+
+	s.gmux.Update(func() {
+		if s.closed {
+			err = ErrClosed
+			return
+		}
+
+		// Check again in case the generator was opened while we were waiting for the lock
+		if g, ok = s.gens[key]; ok {
+			return
+		}
+
+		if o, ok = s.opening[key]; ok {
+			return
+		}
+
+		o = &opening{done: make(chan struct{})}
+		s.opening[key] = o
+		owner = true
+	})
+
+	switch {
+	case err != nil || g != nil:
+		return
+	case !owner:
+		<-o.done
+		return o.g, o.err
+	}
+
+	defer close(o.done)
+	o.g, o.err = s.open(key)
+
+	s.gmux.Update(func() {
+		delete(s.opening, key)
+		if o.err != nil {
+			return
+		}
+
+		if s.closed {
+			// The server was closed while the generator was being opened
+			closeGenerator(o.g)
+			o.g, o.err = nil, ErrClosed
+			return
+		}
+
+		s.gens[key] = o.g
+	})
+
+	return o.g, o.err
+}
+
+// closeGenerator will close the provided generator if it implements io.Closer
+func closeGenerator(g Source) (err error) {
+	if c, ok := g.(io.Closer); ok {
+		err = c.Close()
+	}
+
+	return
+}
+
+// opening is a generator which is being opened
+type opening struct {
+	// Closed once the open has completed
+	done chan struct{}
+	g    Source
+	err  error
+}
+
+// Close will close all opened generators
+// Note: Close should be called after the HTTP server has been shut down
+func (s *Server) Close() (err error) {
+	s.gmux.Update(func() {
+		if s.closed {
+			err = ErrClosed
+			return
+		}
+
+		s.closed = true
+		for _, g := range s.gens {
+			if cerr := closeGenerator(g); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+	})
+
+	return
+}
+
+// Response is the response of a next request
+type Response struct {
+	// IDs issued for requests with a width of 128 (default)
+	IDs []idg.ID `json:"ids,omitempty"`
+	// IDs issued for requests with a width of 32
+	IDs32 []idg.ID32 `json:"ids32,omitempty"`
+	// Error message of a failed request
+	Error string `json:"error,omitempty"`
+}
+
+// writeError will write an error response
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&Response{Error: err.Error()})
+}
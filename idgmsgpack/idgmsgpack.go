@@ -0,0 +1,69 @@
+// Package idgmsgpack provides msgpack extension types for idg.ID and idg.ID32
+package idgmsgpack
+
+import (
+	"reflect"
+
+	"github.com/PathDNA/idg"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+const (
+	// ExtID is the msgpack extension type of ID
+	ExtID int8 = 16
+	// ExtID32 is the msgpack extension type of ID32
+	ExtID32 int8 = 17
+)
+
+// Register will register ID and ID32 as msgpack extension types so they are encoded as
+// fixext 16 and fixext 8 rather than arrays of bytes
+// Note: Extension types are global to the msgpack package, call Register once during
+// initialization. Values, pointers and by-value struct fields are all supported
+func Register() {
+	msgpack.RegisterExtEncoder(ExtID, idg.ID{}, encodeID)
+	msgpack.RegisterExtDecoder(ExtID, idg.ID{}, decodeID)
+	msgpack.RegisterExtEncoder(ExtID32, idg.ID32{}, encodeID32)
+	msgpack.RegisterExtDecoder(ExtID32, idg.ID32{}, decodeID32)
+}
+
+// encodeID will return the extension payload of an ID
+func encodeID(_ *msgpack.Encoder, v reflect.Value) (out []byte, err error) {
+	id := v.Interface().(idg.ID)
+	return id[:], nil
+}
+
+// decodeID will read the extension payload of an ID
+func decodeID(d *msgpack.Decoder, v reflect.Value, n int) (err error) {
+	var id idg.ID
+	if n != len(id) {
+		return idg.ErrInvalidLength
+	}
+
+	if err = d.ReadFull(id[:]); err != nil {
+		return
+	}
+
+	v.Set(reflect.ValueOf(id))
+	return
+}
+
+// encodeID32 will return the extension payload of an ID32
+func encodeID32(_ *msgpack.Encoder, v reflect.Value) (out []byte, err error) {
+	id := v.Interface().(idg.ID32)
+	return id[:], nil
+}
+
+// decodeID32 will read the extension payload of an ID32
+func decodeID32(d *msgpack.Decoder, v reflect.Value, n int) (err error) {
+	var id idg.ID32
+	if n != len(id) {
+		return idg.ErrInvalidLength
+	}
+
+	if err = d.ReadFull(id[:]); err != nil {
+		return
+	}
+
+	v.Set(reflect.ValueOf(id))
+	return
+}
@@ -0,0 +1,76 @@
+package idgmsgpack
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PathDNA/idg"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type testStruct struct {
+	ID   idg.ID   `msgpack:"id"`
+	ID32 idg.ID32 `msgpack:"id32"`
+	Ptr  *idg.ID  `msgpack:"ptr"`
+	Nil  *idg.ID  `msgpack:"nil"`
+}
+
+func TestMsgpack(t *testing.T) {
+	Register()
+	now := time.Now()
+	id := idg.Compose(1337, now, idg.LayoutIndexFirst)
+	b, err := msgpack.Marshal(&id)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fixext 16 header (0xd8), extension type and 16 bytes
+	if len(b) != 18 || b[0] != 0xd8 || int8(b[1]) != ExtID {
+		t.Fatalf("invalid msgpack encoding: %x", b)
+	}
+
+	v7 := idg.Compose(1337, now, idg.LayoutUUIDv7)
+	ts := testStruct{ID: id, ID32: idg.Compose32(1337, now), Ptr: &v7}
+	if b, err = msgpack.Marshal(&ts); err != nil {
+		t.Fatal(err)
+	}
+
+	var nts testStruct
+	if err = msgpack.Unmarshal(b, &nts); err != nil {
+		t.Fatal(err)
+	}
+
+	if nts.ID != ts.ID || nts.ID32 != ts.ID32 || nts.Ptr == nil || *nts.Ptr != v7 || nts.Nil != nil {
+		t.Fatalf("values do not match: %v / %v", ts, nts)
+	}
+
+	// Values and by-value struct fields are not addressable when marshalled by value
+	if b, err = msgpack.Marshal(id); err != nil {
+		t.Fatal(err)
+	} else if len(b) != 18 || b[0] != 0xd8 || int8(b[1]) != ExtID {
+		t.Fatalf("invalid msgpack encoding: %x", b)
+	}
+
+	if b, err = msgpack.Marshal(ts); err != nil {
+		t.Fatal(err)
+	}
+
+	nts = testStruct{}
+	if err = msgpack.Unmarshal(b, &nts); err != nil {
+		t.Fatal(err)
+	}
+
+	if nts.ID != ts.ID || nts.ID32 != ts.ID32 || nts.Ptr == nil || *nts.Ptr != v7 || nts.Nil != nil {
+		t.Fatalf("values do not match: %v / %v", ts, nts)
+	}
+
+	// Extension values decode into interfaces as IDs
+	var v interface{}
+	if err = msgpack.Unmarshal(b, &v); err != nil {
+		t.Fatal(err)
+	}
+
+	if m, _ := v.(map[string]interface{}); m["id"] != id {
+		t.Fatalf("invalid interface value: %v", v)
+	}
+}
@@ -0,0 +1,67 @@
+// Package idgotel is an OpenTelemetry implementation of idg.Tracer
+package idgotel
+
+import (
+	"context"
+
+	"github.com/PathDNA/idg"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Instrumentation name of the tracer
+const tracerName = "github.com/PathDNA/idg"
+
+// Ensure Tracer implements idg.Tracer
+var _ idg.Tracer = &Tracer{}
+
+// New will return a new tracer utilizing the provided tracer provider. Spans have the
+// idg.key, idg.backend and idg.block_size attributes
+//
+// Usage:
+//
+//	tr := idgotel.New(otel.GetTracerProvider())
+//	gen, err := idg.NewPersistent("orders", "./data", idg.WithTracer(tr))
+func New(tp trace.TracerProvider) *Tracer {
+	var t Tracer
+	t.t = tp.Tracer(tracerName)
+	return &t
+}
+
+// WithTracerProvider will return an option which enables OpenTelemetry spans utilizing
+// the provided tracer provider, see New
+func WithTracerProvider(tp trace.TracerProvider) idg.Option {
+	return idg.WithTracer(New(tp))
+}
+
+// Tracer is an OpenTelemetry implementation of idg.Tracer
+type Tracer struct {
+	t trace.Tracer
+}
+
+// Start will start an OpenTelemetry span
+func (t *Tracer) Start(ctx context.Context, name string, attrs idg.SpanAttributes) (context.Context, idg.Span) {
+	ctx, span := t.t.Start(ctx, name, trace.WithAttributes(
+		attribute.String("idg.key", attrs.Key),
+		attribute.String("idg.backend", attrs.Backend),
+		attribute.Int64("idg.block_size", int64(attrs.BlockSize)),
+	))
+
+	return ctx, spanOf{span}
+}
+
+// spanOf is an idg.Span of an OpenTelemetry span
+type spanOf struct {
+	s trace.Span
+}
+
+// End will end the span, recording the error when set
+func (s spanOf) End(err error) {
+	if err != nil {
+		s.s.RecordError(err)
+		s.s.SetStatus(codes.Error, err.Error())
+	}
+
+	s.s.End()
+}
@@ -0,0 +1,109 @@
+package idgotel
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/PathDNA/idg"
+	"github.com/PathDNA/turtleDB"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func newTestTracer() (tp *sdktrace.TracerProvider, exp *tracetest.InMemoryExporter) {
+	exp = tracetest.NewInMemoryExporter()
+	tp = sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	return
+}
+
+func TestPIDG(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	tp, exp := newTestTracer()
+	p, err := idg.NewPersistent("traced", "./test_data", WithTracerProvider(tp))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ctx, parent := tp.Tracer("test").Start(context.Background(), "request")
+	if _, err = p.ReserveContext(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+	parent.End()
+
+	spans := exp.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("invalid number of spans, expected %d and received %d", 3, len(spans))
+	}
+
+	// Spans are exported as they end, persist ends first
+	persist, reserve := spans[0], spans[1]
+	if persist.Name != "idg.persist" || reserve.Name != "idg.reserve" {
+		t.Fatalf("invalid span names: %s, %s", persist.Name, reserve.Name)
+	}
+
+	if persist.Parent.SpanID() != reserve.SpanContext.SpanID() || reserve.Parent.SpanID() != spans[2].SpanContext.SpanID() {
+		t.Fatal("invalid span hierarchy")
+	}
+
+	testAttributes(t, reserve.Attributes, "traced", "file", 10)
+}
+
+func TestTIDG(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	tp, exp := newTestTracer()
+	fm := turtleDB.FuncsMap{}
+	tidg := idg.NewTIDG("traced", fm, WithTracerProvider(tp))
+	db, err := turtleDB.New("tidg_trace_test", "./test_data", fm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err = db.Update(func(txn turtleDB.Txn) (err error) {
+		_, err = tidg.Next(txn)
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	spans := exp.GetSpans()
+	if len(spans) != 2 {
+		t.Fatalf("invalid number of spans, expected %d and received %d", 2, len(spans))
+	}
+
+	testAttributes(t, spans[1].Attributes, "traced", "turtledb", 1)
+}
+
+func TestSpanError(t *testing.T) {
+	tp, exp := newTestTracer()
+	_, span := New(tp).Start(context.Background(), "idg.persist", idg.SpanAttributes{Key: "failed"})
+	span.End(errors.New("persist failed"))
+
+	spans := exp.GetSpans()
+	if len(spans) != 1 || spans[0].Status.Code != codes.Error || len(spans[0].Events) == 0 {
+		t.Fatalf("span did not record the error: %+v", spans)
+	}
+}
+
+func testAttributes(t *testing.T, attrs []attribute.KeyValue, key, backend string, n int64) {
+	expected := map[attribute.Key]attribute.Value{
+		"idg.key":        attribute.StringValue(key),
+		"idg.backend":    attribute.StringValue(backend),
+		"idg.block_size": attribute.Int64Value(n),
+	}
+
+	for _, kv := range attrs {
+		if v, ok := expected[kv.Key]; ok && v == kv.Value {
+			delete(expected, kv.Key)
+		}
+	}
+
+	if len(expected) != 0 {
+		t.Fatalf("missing attributes: %v", expected)
+	}
+}
@@ -0,0 +1,57 @@
+package idgpb
+
+import (
+	"github.com/PathDNA/idg"
+)
+
+// New will return the Protocol Buffers representation of an ID
+// Note: The value is the raw 16 bytes of the ID
+func New(id idg.ID) (m *ID) {
+	m = &ID{}
+	if !id.IsEmpty() {
+		m.Value = id[:]
+	}
+
+	return
+}
+
+// New32 will return the Protocol Buffers representation of an ID32
+// Note: The value is the raw 8 bytes of the ID32
+func New32(id idg.ID32) (m *ID) {
+	m = &ID{}
+	if !id.IsEmpty() {
+		m.Value = id[:]
+	}
+
+	return
+}
+
+// AsID will return the ID of the message
+// Note: A nil message or empty value is returned as an empty ID, any value which is not
+// 16 bytes will return idg.ErrInvalidLength
+func (x *ID) AsID() (id idg.ID, err error) {
+	switch v := x.GetValue(); len(v) {
+	case 0:
+	case len(id):
+		copy(id[:], v)
+	default:
+		err = idg.ErrInvalidLength
+	}
+
+	return
+}
+
+// AsID32 will return the ID32 of the message
+// Note: A nil message or empty value is returned as an empty ID32, any value which is not
+// 8 bytes will return idg.ErrInvalidLength
+func (x *ID) AsID32() (id idg.ID32, err error) {
+	switch v := x.GetValue(); len(v) {
+	case 0:
+	case len(id):
+		copy(id[:], v)
+	default:
+		err = idg.ErrInvalidLength
+	}
+
+	return
+}
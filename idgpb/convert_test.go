@@ -0,0 +1,103 @@
+package idgpb
+
+import (
+	"testing"
+	"time"
+
+	"github.com/PathDNA/idg"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestProto(t *testing.T) {
+	id := idg.Compose(1337, time.Now(), idg.LayoutIndexFirst)
+	b, err := proto.Marshal(New(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m ID
+	if err = proto.Unmarshal(b, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	var nid idg.ID
+	if nid, err = m.AsID(); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	id32 := idg.Compose32(1337, time.Now())
+	var nid32 idg.ID32
+	if nid32, err = New32(id32).AsID32(); err != nil {
+		t.Fatal(err)
+	} else if nid32 != id32 {
+		t.Fatalf("ID's do not match: %v / %v", id32.Bytes(), nid32.Bytes())
+	}
+
+	// Nil messages and empty values are empty IDs
+	var nilm *ID
+	if nid, err = nilm.AsID(); err != nil || !nid.IsEmpty() {
+		t.Fatalf("invalid nil message ID: %v (%v)", nid.Bytes(), err)
+	}
+
+	if New(idg.ID{}).Value != nil {
+		t.Fatal("expected an empty value for an empty ID")
+	}
+}
+
+func TestProtoSize(t *testing.T) {
+	// Encodings must not affect the message, the value is always the raw bytes
+	t.Cleanup(func() { idg.SetEncoding(idg.Base64URL) })
+	idg.SetEncoding(idg.Hex)
+
+	id := idg.Compose(1337, time.Now(), idg.LayoutIndexFirst)
+	b, err := proto.Marshal(New(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Tag, length and 16 raw bytes
+	if len(b) != 2+len(id) {
+		t.Fatalf("invalid message length, expected %d and received %d", 2+len(id), len(b))
+	}
+
+	if b, err = proto.Marshal(New32(idg.Compose32(1337, time.Now()))); err != nil {
+		t.Fatal(err)
+	} else if len(b) != 2+8 {
+		t.Fatalf("invalid message length, expected %d and received %d", 2+8, len(b))
+	}
+
+	// Values of the wrong length are rejected rather than truncated
+	if _, err = (&ID{Value: id[:8]}).AsID(); err != idg.ErrInvalidLength {
+		t.Fatalf("invalid error, expected %v and received %v", idg.ErrInvalidLength, err)
+	}
+
+	if _, err = (&ID{Value: id[:]}).AsID32(); err != idg.ErrInvalidLength {
+		t.Fatalf("invalid error, expected %v and received %v", idg.ErrInvalidLength, err)
+	}
+
+	// The string form of an ID is no longer accepted
+	if _, err = (&ID{Value: []byte(id.String())}).AsID(); err != idg.ErrInvalidLength {
+		t.Fatalf("invalid error, expected %v and received %v", idg.ErrInvalidLength, err)
+	}
+}
+
+func TestProtoJSON(t *testing.T) {
+	id := idg.Compose(1337, time.Now(), idg.LayoutIndexFirst)
+	pb, err := protojson.Marshal(New(id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var m ID
+	if err = protojson.Unmarshal(pb, &m); err != nil {
+		t.Fatal(err)
+	}
+
+	var nid idg.ID
+	if nid, err = m.AsID(); err != nil || nid != id {
+		t.Fatalf("invalid round trip: %v / %v (%v)", id.Bytes(), nid.Bytes(), err)
+	}
+}
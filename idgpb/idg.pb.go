@@ -0,0 +1,126 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: idg.proto
+
+package idgpb
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// ID is the well-known representation of an idg ID. The value holds the raw bytes
+// of the ID, 16 bytes for an ID and 8 bytes for an ID32. An empty value is an empty ID
+// Note: As with any bytes field, the protojson form of the value is standard base64 and
+// does not match the JSON form of the ID
+type ID struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Value         []byte                 `protobuf:"bytes,1,opt,name=value,proto3" json:"value,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ID) Reset() {
+	*x = ID{}
+	mi := &file_idg_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ID) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ID) ProtoMessage() {}
+
+func (x *ID) ProtoReflect() protoreflect.Message {
+	mi := &file_idg_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ID.ProtoReflect.Descriptor instead.
+func (*ID) Descriptor() ([]byte, []int) {
+	return file_idg_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ID) GetValue() []byte {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+var File_idg_proto protoreflect.FileDescriptor
+
+const file_idg_proto_rawDesc = "" +
+	"\n" +
+	"\tidg.proto\x12\x03idg\"\x1a\n" +
+	"\x02ID\x12\x14\n" +
+	"\x05value\x18\x01 \x01(\fR\x05valueB\x1eZ\x1cgithub.com/PathDNA/idg/idgpbb\x06proto3"
+
+var (
+	file_idg_proto_rawDescOnce sync.Once
+	file_idg_proto_rawDescData []byte
+)
+
+func file_idg_proto_rawDescGZIP() []byte {
+	file_idg_proto_rawDescOnce.Do(func() {
+		file_idg_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_idg_proto_rawDesc), len(file_idg_proto_rawDesc)))
+	})
+	return file_idg_proto_rawDescData
+}
+
+var file_idg_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_idg_proto_goTypes = []any{
+	(*ID)(nil), // 0: idg.ID
+}
+var file_idg_proto_depIdxs = []int32{
+	0, // [0:0] is the sub-list for method output_type
+	0, // [0:0] is the sub-list for method input_type
+	0, // [0:0] is the sub-list for extension type_name
+	0, // [0:0] is the sub-list for extension extendee
+	0, // [0:0] is the sub-list for field type_name
+}
+
+func init() { file_idg_proto_init() }
+func file_idg_proto_init() {
+	if File_idg_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_idg_proto_rawDesc), len(file_idg_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   1,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_idg_proto_goTypes,
+		DependencyIndexes: file_idg_proto_depIdxs,
+		MessageInfos:      file_idg_proto_msgTypes,
+	}.Build()
+	File_idg_proto = out.File
+	file_idg_proto_goTypes = nil
+	file_idg_proto_depIdxs = nil
+}
@@ -0,0 +1,125 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v5.29.3
+// source: idg_service.proto
+
+package idgpb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	IDG_Lease_FullMethodName = "/idg.IDG/Lease"
+)
+
+// IDGClient is the client API for IDG service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// IDG leases contiguous index blocks to clients
+type IDGClient interface {
+	// Lease will lease a block of indexes for each request received on the stream.
+	// A request acknowledges every lease of the stream except the latest. Leases which
+	// are outstanding when the stream ends are never reissued
+	Lease(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LeaseRequest, LeaseResponse], error)
+}
+
+type iDGClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewIDGClient(cc grpc.ClientConnInterface) IDGClient {
+	return &iDGClient{cc}
+}
+
+func (c *iDGClient) Lease(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[LeaseRequest, LeaseResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &IDG_ServiceDesc.Streams[0], IDG_Lease_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LeaseRequest, LeaseResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IDG_LeaseClient = grpc.BidiStreamingClient[LeaseRequest, LeaseResponse]
+
+// IDGServer is the server API for IDG service.
+// All implementations must embed UnimplementedIDGServer
+// for forward compatibility.
+//
+// IDG leases contiguous index blocks to clients
+type IDGServer interface {
+	// Lease will lease a block of indexes for each request received on the stream.
+	// A request acknowledges every lease of the stream except the latest. Leases which
+	// are outstanding when the stream ends are never reissued
+	Lease(grpc.BidiStreamingServer[LeaseRequest, LeaseResponse]) error
+	mustEmbedUnimplementedIDGServer()
+}
+
+// UnimplementedIDGServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedIDGServer struct{}
+
+func (UnimplementedIDGServer) Lease(grpc.BidiStreamingServer[LeaseRequest, LeaseResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Lease not implemented")
+}
+func (UnimplementedIDGServer) mustEmbedUnimplementedIDGServer() {}
+func (UnimplementedIDGServer) testEmbeddedByValue()             {}
+
+// UnsafeIDGServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to IDGServer will
+// result in compilation errors.
+type UnsafeIDGServer interface {
+	mustEmbedUnimplementedIDGServer()
+}
+
+func RegisterIDGServer(s grpc.ServiceRegistrar, srv IDGServer) {
+	// If the following call pancis, it indicates UnimplementedIDGServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&IDG_ServiceDesc, srv)
+}
+
+func _IDG_Lease_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(IDGServer).Lease(&grpc.GenericServerStream[LeaseRequest, LeaseResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type IDG_LeaseServer = grpc.BidiStreamingServer[LeaseRequest, LeaseResponse]
+
+// IDG_ServiceDesc is the grpc.ServiceDesc for IDG service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var IDG_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "idg.IDG",
+	HandlerType: (*IDGServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Lease",
+			Handler:       _IDG_Lease_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "idg_service.proto",
+}
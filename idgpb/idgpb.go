@@ -0,0 +1,6 @@
+// Package idgpb holds the Protocol Buffers representation of idg IDs and the IDG lease service
+// Please see New and ID.AsID for conversion helpers and idgrpc for the service
+package idgpb
+
+//go:generate protoc --go_out=. --go_opt=paths=source_relative idg.proto
+//go:generate protoc --go_out=. --go_opt=paths=source_relative --go-grpc_out=. --go-grpc_opt=paths=source_relative idg_service.proto
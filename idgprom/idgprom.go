@@ -0,0 +1,124 @@
+// Package idgprom is a Prometheus implementation of idg.Metrics
+package idgprom
+
+import (
+	"time"
+
+	"github.com/PathDNA/idg"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Ensure Collector implements idg.Metrics and prometheus.Collector
+var (
+	_ idg.Metrics          = &Collector{}
+	_ prometheus.Collector = &Collector{}
+)
+
+// New will return a new collector with the provided metric namespace (e.g. "idg")
+//
+// Usage:
+//
+//	c := idgprom.New("idg")
+//	prometheus.MustRegister(c)
+//	gen, err := idg.NewPersistent("orders", "./data", idg.WithMetrics(c))
+func New(namespace string) *Collector {
+	var c Collector
+	c.issued = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "ids_issued_total",
+		Help:      "Number of IDs issued.",
+	}, []string{"key"})
+
+	c.persistDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "persist_duration_seconds",
+		Help:      "Latency of persisting an index.",
+		Buckets:   prometheus.ExponentialBuckets(1e-6, 4, 10),
+	}, []string{"key"})
+
+	c.persistErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "persist_errors_total",
+		Help:      "Number of failed index persists.",
+	}, []string{"key"})
+
+	c.syncErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "fsync_errors_total",
+		Help:      "Number of failed persistent file syncs.",
+	}, []string{"key"})
+
+	c.refills = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "block_refills_total",
+		Help:      "Number of index blocks reserved by leased generators.",
+	}, []string{"key"})
+
+	c.clockRegressions = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "clock_regressions_total",
+		Help:      "Number of times the clock was observed moving backwards.",
+	}, []string{"key"})
+
+	return &c
+}
+
+// Collector is a Prometheus collector of generator metrics
+type Collector struct {
+	issued           *prometheus.CounterVec
+	persistDuration  *prometheus.HistogramVec
+	persistErrors    *prometheus.CounterVec
+	syncErrors       *prometheus.CounterVec
+	refills          *prometheus.CounterVec
+	clockRegressions *prometheus.CounterVec
+}
+
+// Issued will count n issued IDs
+func (c *Collector) Issued(key string, n uint64) {
+	c.issued.WithLabelValues(key).Add(float64(n))
+}
+
+// Persisted will observe the persist latency and count persist errors
+func (c *Collector) Persisted(key string, d time.Duration, err error) {
+	if err != nil {
+		c.persistErrors.WithLabelValues(key).Inc()
+		return
+	}
+
+	c.persistDuration.WithLabelValues(key).Observe(d.Seconds())
+}
+
+// SyncFailed will count a failed sync
+func (c *Collector) SyncFailed(key string, err error) {
+	c.syncErrors.WithLabelValues(key).Inc()
+}
+
+// Refilled will count a reserved block
+func (c *Collector) Refilled(key string, n uint64) {
+	c.refills.WithLabelValues(key).Inc()
+}
+
+// ClockRegressed will count a clock regression
+func (c *Collector) ClockRegressed(key string, d time.Duration) {
+	c.clockRegressions.WithLabelValues(key).Inc()
+}
+
+// Describe will send the descriptors of the metrics to ch
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	c.issued.Describe(ch)
+	c.persistDuration.Describe(ch)
+	c.persistErrors.Describe(ch)
+	c.syncErrors.Describe(ch)
+	c.refills.Describe(ch)
+	c.clockRegressions.Describe(ch)
+}
+
+// Collect will send the metrics to ch
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	c.issued.Collect(ch)
+	c.persistDuration.Collect(ch)
+	c.persistErrors.Collect(ch)
+	c.syncErrors.Collect(ch)
+	c.refills.Collect(ch)
+	c.clockRegressions.Collect(ch)
+}
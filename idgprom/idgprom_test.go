@@ -0,0 +1,72 @@
+package idgprom
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/PathDNA/idg"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestCollector(t *testing.T) {
+	dir, err := os.MkdirTemp("", "idgprom")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	c := New("idg")
+	reg := prometheus.NewPedanticRegistry()
+	if err = reg.Register(c); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := idg.NewPersistent("orders", dir, idg.WithMetrics(c), idg.WithSync())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err = p.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	l, err := idg.NewLeased(p, 10, idg.WithMetrics(c), idg.WithKey("leased"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = l.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if v := testutil.ToFloat64(c.issued.WithLabelValues("orders")); v != 3 {
+		t.Fatalf("invalid issued count, expected %v and received %v", 3, v)
+	}
+
+	if v := testutil.ToFloat64(c.issued.WithLabelValues("leased")); v != 1 {
+		t.Fatalf("invalid issued count, expected %v and received %v", 1, v)
+	}
+
+	if v := testutil.ToFloat64(c.refills.WithLabelValues("leased")); v != 1 {
+		t.Fatalf("invalid refill count, expected %v and received %v", 1, v)
+	}
+
+	// Next (3) and Reserve (1)
+	if n := testutil.CollectAndCount(c, "idg_persist_duration_seconds"); n != 1 {
+		t.Fatalf("invalid number of histograms, expected %d and received %d", 1, n)
+	}
+
+	c.ClockRegressed("orders", time.Second)
+	if v := testutil.ToFloat64(c.clockRegressions.WithLabelValues("orders")); v != 1 {
+		t.Fatalf("invalid clock regression count, expected %v and received %v", 1, v)
+	}
+
+	if _, err = reg.Gather(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,145 @@
+package idgrpc
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/PathDNA/idg"
+	"github.com/PathDNA/idg/idgpb"
+	"github.com/missionMeteora/toolkit/errors"
+	"google.golang.org/grpc"
+)
+
+const (
+	// ErrInvalidKey is returned when a key is not valid
+	ErrInvalidKey = errors.Error("invalid key, keys must be 1 to 64 alphanumeric, dash or underscore characters")
+	// ErrInvalidLease is returned when a lease does not match the request
+	ErrInvalidLease = errors.Error("invalid lease")
+	// ErrClosed is returned when an action is performed on a closed client
+	ErrClosed = errors.Error("client is closed")
+)
+
+// Ensure Client implements idg.BlockSource
+var _ idg.BlockSource = &Client{}
+
+// NewClient will return a new lease client for the provided key
+func NewClient(cc grpc.ClientConnInterface, key string) (c *Client, err error) {
+	if !idg.IsValidKey(key) {
+		err = ErrInvalidKey
+		return
+	}
+
+	var cl Client
+	cl.c = idgpb.NewIDGClient(cc)
+	cl.key = key
+	c = &cl
+	return
+}
+
+// NewGenerator will return a new generator which issues IDs from blocks of the provided size
+// leased from the service. The next block is prefetched before the current block is exhausted
+func NewGenerator(cc grpc.ClientConnInterface, key string, size uint64, ops ...idg.Option) (l *idg.LIDG, err error) {
+	var c *Client
+	if c, err = NewClient(cc, key); err != nil {
+		return
+	}
+
+	return idg.NewLeased(c, size, ops...)
+}
+
+// Client is a lease client, it implements idg.BlockSource
+type Client struct {
+	mux sync.Mutex
+
+	c idgpb.IDGClient
+	// Key of the generator
+	key string
+	// Lease stream, opened on demand
+	stream idgpb.IDG_LeaseClient
+	cancel context.CancelFunc
+	// Closed state
+	closed bool
+}
+
+// Reserve will lease a block of n indexes and return the first index of the block
+func (c *Client) Reserve(n uint64) (start uint64, err error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.closed {
+		err = ErrClosed
+		return
+	}
+
+	if c.stream == nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		if c.stream, err = c.c.Lease(ctx); err != nil {
+			cancel()
+			return
+		}
+
+		c.cancel = cancel
+	}
+
+	req := idgpb.LeaseRequest{Key: c.key, Count: n}
+	var resp *idgpb.LeaseResponse
+	if err = c.stream.Send(&req); err == nil {
+		resp, err = c.stream.Recv()
+	}
+
+	if err != nil {
+		// The stream is broken, a new stream will be opened by the following call
+		c.reset()
+		return
+	}
+
+	if resp.GetKey() != c.key || resp.GetCount() != n {
+		err = ErrInvalidLease
+		return
+	}
+
+	start = resp.GetStart()
+	return
+}
+
+// reset will cancel and clear the current stream
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (c *Client) reset() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+
+	c.stream = nil
+	c.cancel = nil
+}
+
+// Close will close the lease stream
+// Note: Unused indexes of leased blocks are not reissued
+func (c *Client) Close() (err error) {
+	c.mux.Lock()
+	defer c.mux.Unlock()
+
+	if c.closed {
+		return ErrClosed
+	}
+
+	c.closed = true
+	if c.stream == nil {
+		return
+	}
+
+	err = c.stream.CloseSend()
+	// Wait for the server to end the stream so the leases are released cleanly
+	for err == nil {
+		_, err = c.stream.Recv()
+	}
+
+	if err == io.EOF {
+		err = nil
+	}
+
+	c.reset()
+	return
+}
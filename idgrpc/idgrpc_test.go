@@ -0,0 +1,326 @@
+package idgrpc
+
+import (
+	"context"
+	"net"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PathDNA/idg"
+	"github.com/PathDNA/idg/idgpb"
+	"github.com/PathDNA/turtleDB"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func newTestConn(t *testing.T, s *Server) (cc *grpc.ClientConn) {
+	lis := bufconn.Listen(1 << 20)
+	gs := grpc.NewServer()
+	idgpb.RegisterIDGServer(gs, s)
+	go gs.Serve(lis)
+	t.Cleanup(gs.Stop)
+
+	dial := func(ctx context.Context, _ string) (net.Conn, error) {
+		return lis.DialContext(ctx)
+	}
+
+	var err error
+	if cc, err = grpc.NewClient("passthrough:///bufnet", grpc.WithContextDialer(dial), grpc.WithTransportCredentials(insecure.NewCredentials())); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { cc.Close() })
+	return
+}
+
+func newTestDir(t *testing.T) (dir string) {
+	dir, err := os.MkdirTemp("", "idgrpc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	return
+}
+
+func TestGenerator(t *testing.T) {
+	s := NewServer(PersistentOpener(newTestDir(t)))
+	defer s.Close()
+	cc := newTestConn(t, s)
+
+	g, err := NewGenerator(cc, "orders", 32)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mux  sync.Mutex
+		seen = make(map[uint64]struct{})
+	)
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 250; j++ {
+				id, err := g.Next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				idx, _ := id.Index()
+				mux.Lock()
+				seen[idx] = struct{}{}
+				mux.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	if len(seen) != 1000 {
+		t.Fatalf("invalid number of unique indexes, expected %d and received %d", 1000, len(seen))
+	}
+
+	if err = g.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	st := s.Stats("orders")
+	if st.Indexes != st.Leases*32 || st.Indexes < 1000 {
+		t.Fatalf("invalid stats: %+v", st)
+	}
+
+	if st.Abandoned != 0 {
+		t.Fatalf("invalid number of abandoned leases, expected %d and received %d", 0, st.Abandoned)
+	}
+
+	if n := len(s.Leases()); n != 0 {
+		t.Fatalf("invalid number of outstanding leases, expected %d and received %d", 0, n)
+	}
+}
+
+func TestAcknowledgedLeases(t *testing.T) {
+	s := NewServer(PersistentOpener(newTestDir(t)))
+	defer s.Close()
+	cc := newTestConn(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := idgpb.NewIDGClient(cc).Lease(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Each request acknowledges the leases before the latest, a long-lived stream does not
+	// accumulate outstanding leases
+	for i := 0; i < 50; i++ {
+		if err = stream.Send(&idgpb.LeaseRequest{Key: "orders", Count: 10}); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err = stream.Recv(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if n := len(s.Leases()); n != 2 {
+		t.Fatalf("invalid number of outstanding leases, expected %d and received %d", 2, n)
+	}
+
+	// Only the unacknowledged leases are abandoned
+	cancel()
+	for i := 0; i < 1000 && s.Stats("orders").Abandoned == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if st := s.Stats("orders"); st.Abandoned != 2 || st.AbandonedIndexes != 20 || st.Leases != 50 {
+		t.Fatalf("invalid stats: %+v", st)
+	}
+}
+
+func TestAbandonedLease(t *testing.T) {
+	dir := newTestDir(t)
+	s := NewServer(PersistentOpener(dir))
+	cc := newTestConn(t, s)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream, err := idgpb.NewIDGClient(cc).Lease(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = stream.Send(&idgpb.LeaseRequest{Key: "orders", Count: 100}); err != nil {
+		t.Fatal(err)
+	}
+
+	var resp *idgpb.LeaseResponse
+	if resp, err = stream.Recv(); err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.GetStart() != 0 || resp.GetCount() != 100 {
+		t.Fatalf("invalid lease: %v", resp)
+	}
+
+	if n := len(s.Leases()); n != 1 {
+		t.Fatalf("invalid number of outstanding leases, expected %d and received %d", 1, n)
+	}
+
+	// Simulate a client crash
+	cancel()
+	var c *Client
+	if c, err = NewClient(cc, "orders"); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	var start uint64
+	if start, err = c.Reserve(10); err != nil {
+		t.Fatal(err)
+	}
+
+	if start != 100 {
+		t.Fatalf("invalid start, expected %d and received %d", 100, start)
+	}
+
+	// The crashed stream ends asynchronously, wait for it to be accounted
+	for i := 0; i < 1000 && s.Stats("orders").Abandoned == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if st := s.Stats("orders"); st.Abandoned != 1 || st.AbandonedIndexes != 100 {
+		t.Fatalf("invalid stats: %+v", st)
+	}
+
+	// Restart the server and ensure leased blocks are not reissued
+	if err = s.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	s = NewServer(PersistentOpener(dir))
+	defer s.Close()
+
+	if c, err = NewClient(newTestConn(t, s), "orders"); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if start, err = c.Reserve(1); err != nil {
+		t.Fatal(err)
+	}
+
+	if start != 110 {
+		t.Fatalf("invalid start, expected %d and received %d", 110, start)
+	}
+}
+
+func TestTurtleOpener(t *testing.T) {
+	fm := turtleDB.FuncsMap{}
+	idg.NewTIDG("orders", fm)
+	db, err := turtleDB.New("idgrpc_test", newTestDir(t), fm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	s := NewServer(TurtleOpener(db, fm))
+	defer s.Close()
+
+	var c *Client
+	if c, err = NewClient(newTestConn(t, s), "orders"); err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for _, expected := range []uint64{0, 50} {
+		var start uint64
+		if start, err = c.Reserve(50); err != nil {
+			t.Fatal(err)
+		}
+
+		if start != expected {
+			t.Fatalf("invalid start, expected %d and received %d", expected, start)
+		}
+	}
+}
+
+func TestInvalidRequest(t *testing.T) {
+	s := NewServer(PersistentOpener(newTestDir(t)))
+	defer s.Close()
+	cc := newTestConn(t, s)
+
+	if _, err := NewClient(cc, "../orders"); err != ErrInvalidKey {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidKey, err)
+	}
+
+	c, err := NewClient(cc, "orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if _, err = c.Reserve(MaxBlockSize + 1); err == nil {
+		t.Fatal("expected error for an oversized block")
+	}
+
+	// The client should recover with a new stream
+	if _, err = c.Reserve(1); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSourceOpening(t *testing.T) {
+	var (
+		mux     sync.Mutex
+		opens   = make(map[string]int)
+		entered = make(chan struct{})
+		release = make(chan struct{})
+	)
+
+	open := PersistentOpener(newTestDir(t))
+	s := NewServer(func(key string) (idg.BlockSource, error) {
+		mux.Lock()
+		opens[key]++
+		mux.Unlock()
+
+		if key == "slow" {
+			close(entered)
+			<-release
+		}
+
+		return open(key)
+	})
+	defer s.Close()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := s.lease("slow", 1); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+
+	<-entered
+	// Sources are opened outside of the server lock, other keys are not blocked
+	if _, err := s.lease("fast", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	close(release)
+	wg.Wait()
+
+	if opens["slow"] != 1 || opens["fast"] != 1 {
+		t.Fatalf("invalid number of opens: %v", opens)
+	}
+
+	if st := s.Stats("slow"); st.Indexes != 4 {
+		t.Fatalf("invalid number of indexes, expected %d and received %d", 4, st.Indexes)
+	}
+}
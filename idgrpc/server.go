@@ -0,0 +1,362 @@
+// Package idgrpc is a gRPC index block leasing service and client
+package idgrpc
+
+import (
+	"io"
+	"sort"
+	"time"
+
+	"github.com/PathDNA/atoms"
+	"github.com/PathDNA/idg"
+	"github.com/PathDNA/idg/idgpb"
+	"github.com/PathDNA/turtleDB"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	// MaxBlockSize is the maximum number of indexes leased by a single request
+	MaxBlockSize = 1 << 20
+)
+
+// Ensure Server implements idgpb.IDGServer
+var _ idgpb.IDGServer = &Server{}
+
+// Opener will open the block source for the provided key
+// Note: Block sources which implement io.Closer are closed when the server is closed
+type Opener func(key string) (idg.BlockSource, error)
+
+// PersistentOpener will return an Opener which opens persistent generators within the provided directory
+func PersistentOpener(dir string) Opener {
+	return func(key string) (src idg.BlockSource, err error) {
+		return idg.NewPersistent(key, dir)
+	}
+}
+
+// Updater is implemented by turtleDB databases
+type Updater interface {
+	Update(fn func(turtleDB.Txn) error) error
+}
+
+// TurtleOpener will return an Opener which opens turtleDB-backed generators within the provided database
+// Note: fm must be the FuncsMap the database was initialized with
+func TurtleOpener(db Updater, fm turtleDB.FuncsMap) Opener {
+	return func(key string) (src idg.BlockSource, err error) {
+		t := idg.NewTIDG(key, fm)
+		return &turtleSource{db: db, t: &t}, nil
+	}
+}
+
+// turtleSource is a block source backed by a TIDG
+type turtleSource struct {
+	db Updater
+	t  *idg.TIDG
+}
+
+// Reserve will reserve a block of n indexes within an update transaction
+func (ts *turtleSource) Reserve(n uint64) (start uint64, err error) {
+	err = ts.db.Update(func(txn turtleDB.Txn) (err error) {
+		start, err = ts.t.Reserve(txn, n)
+		return
+	})
+
+	return
+}
+
+// NewServer will return a new server which leases blocks from the sources of the provided opener
+func NewServer(open Opener) *Server {
+	var s Server
+	s.open = open
+	s.srcs = make(map[string]idg.BlockSource)
+	s.opening = make(map[string]*opening)
+	s.active = make(map[uint64]Lease)
+	s.stats = make(map[string]*Stats)
+	return &s
+}
+
+// Server is a gRPC index block leasing service
+// Note: Blocks are reserved (and persisted by the source) before they are sent to the
+// client, so a block is never reissued, even when a client crashes while holding it
+type Server struct {
+	idgpb.UnimplementedIDGServer
+
+	mux atoms.Mux
+	// Block source opener
+	open Opener
+	// Block sources by key
+	srcs map[string]idg.BlockSource
+	// Block sources being opened by key
+	opening map[string]*opening
+	// Outstanding leases by lease ID
+	active map[uint64]Lease
+	// Lease statistics by key
+	stats map[string]*Stats
+	// Last issued lease ID
+	leaseID uint64
+	// Closed state
+	closed bool
+}
+
+// Lease will lease a block of indexes for each request received on the stream
+// Note: A request acknowledges every lease of the stream except the latest, which may still
+// be in use (e.g. the current block of a LIDG while the next block is prefetched). A stream
+// holds at most two outstanding leases regardless of how long it is open
+func (s *Server) Lease(stream idgpb.IDG_LeaseServer) (err error) {
+	// Outstanding leases of this stream
+	var leases []uint64
+	defer func() {
+		// A stream which ends without an error was closed by the client, any other
+		// end (e.g. a client crash) results in the outstanding leases being abandoned
+		s.end(leases, err != nil)
+	}()
+
+	for {
+		var req *idgpb.LeaseRequest
+		if req, err = stream.Recv(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
+
+			return
+		}
+
+		if len(leases) > 1 {
+			// Retire the acknowledged leases, only the latest lease is kept
+			s.end(leases[:len(leases)-1], false)
+			leases = append(leases[:0], leases[len(leases)-1])
+		}
+
+		var l Lease
+		if l, err = s.lease(req.GetKey(), req.GetCount()); err != nil {
+			return
+		}
+
+		leases = append(leases, l.ID)
+		resp := idgpb.LeaseResponse{
+			LeaseId: l.ID,
+			Key:     l.Key,
+			Start:   l.Start,
+			Count:   l.End - l.Start,
+		}
+
+		if err = stream.Send(&resp); err != nil {
+			return
+		}
+	}
+}
+
+// lease will reserve a block of n indexes for the provided key
+func (s *Server) lease(key string, n uint64) (l Lease, err error) {
+	if !idg.IsValidKey(key) {
+		err = status.Error(codes.InvalidArgument, "invalid key")
+		return
+	}
+
+	if n == 0 || n > MaxBlockSize {
+		err = status.Errorf(codes.InvalidArgument, "invalid count, expected 1 to %d", MaxBlockSize)
+		return
+	}
+
+	var src idg.BlockSource
+	if src, err = s.source(key); err != nil {
+		return
+	}
+
+	var start uint64
+	if start, err = src.Reserve(n); err != nil {
+		err = status.Error(codes.Internal, err.Error())
+		return
+	}
+
+	s.mux.Update(func() {
+		s.leaseID++
+		l = Lease{ID: s.leaseID, Key: key, Start: start, End: start + n, Issued: time.Now()}
+		s.active[l.ID] = l
+
+		st := s.getStats(key)
+		st.Leases++
+		st.Indexes += n
+	})
+
+	return
+}
+
+// end will end the provided leases of a stream
+func (s *Server) end(leases []uint64, abandoned bool) {
+	s.mux.Update(func() {
+		for _, id := range leases {
+			l, ok := s.active[id]
+			if !ok {
+				continue
+			}
+
+			delete(s.active, id)
+			if !abandoned {
+				continue
+			}
+
+			st := s.getStats(l.Key)
+			st.Abandoned++
+			st.AbandonedIndexes += l.End - l.Start
+		}
+	})
+}
+
+// getStats will return the stats for the provided key
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (s *Server) getStats(key string) (st *Stats) {
+	var ok bool
+	if st, ok = s.stats[key]; !ok {
+		st = &Stats{}
+		s.stats[key] = st
+	}
+
+	return
+}
+
+// source will return the block source for the provided key, opening it if needed
+// Note: Sources are opened outside of the server lock, concurrent requests for a key
+// which is being opened wait for the pending open
+func (s *Server) source(key string) (src idg.BlockSource, err error) {
+	var (
+		o     *opening
+		owner bool
+	)
+
+	s.mux.Update(func() {
+		if s.closed {
+			err = status.Error(codes.Unavailable, "server is closed")
+			return
+		}
+
+		var ok bool
+		if src, ok = s.srcs[key]; ok {
+			return
+		}
+
+		if o, ok = s.opening[key]; ok {
+			return
+		}
+
+		o = &opening{done: make(chan struct{})}
+		s.opening[key] = o
+		owner = true
+	})
+
+	switch {
+	case err != nil || src != nil:
+		return
+	case !owner:
+		<-o.done
+		return o.src, o.err
+	}
+
+	defer close(o.done)
+	if o.src, o.err = s.open(key); o.err != nil {
+		o.err = status.Error(codes.Internal, o.err.Error())
+	}
+
+	s.mux.Update(func() {
+		delete(s.opening, key)
+		if o.err != nil {
+			return
+		}
+
+		if s.closed {
+			// The server was closed while the source was being opened
+			closeSource(o.src)
+			o.src, o.err = nil, status.Error(codes.Unavailable, "server is closed")
+			return
+		}
+
+		s.srcs[key] = o.src
+	})
+
+	return o.src, o.err
+}
+
+// closeSource will close the provided source if it implements io.Closer
+func closeSource(src idg.BlockSource) (err error) {
+	if c, ok := src.(io.Closer); ok {
+		err = c.Close()
+	}
+
+	return
+}
+
+// opening is a block source which is being opened
+type opening struct {
+	// Closed once the open has completed
+	done chan struct{}
+	src  idg.BlockSource
+	err  error
+}
+
+// Leases will return the outstanding leases ordered by lease ID
+func (s *Server) Leases() (leases []Lease) {
+	s.mux.Read(func() {
+		leases = make([]Lease, 0, len(s.active))
+		for _, l := range s.active {
+			leases = append(leases, l)
+		}
+	})
+
+	sort.Slice(leases, func(i, j int) bool {
+		return leases[i].ID < leases[j].ID
+	})
+
+	return
+}
+
+// Stats will return the lease statistics of the provided key
+func (s *Server) Stats(key string) (st Stats) {
+	s.mux.Read(func() {
+		if sp, ok := s.stats[key]; ok {
+			st = *sp
+		}
+	})
+
+	return
+}
+
+// Close will close all opened block sources
+// Note: Close should be called after the gRPC server has been stopped
+func (s *Server) Close() (err error) {
+	s.mux.Update(func() {
+		if s.closed {
+			err = status.Error(codes.Unavailable, "server is closed")
+			return
+		}
+
+		s.closed = true
+		for _, src := range s.srcs {
+			if cerr := closeSource(src); cerr != nil && err == nil {
+				err = cerr
+			}
+		}
+	})
+
+	return
+}
+
+// Lease is a block of indexes, [Start, End), leased to a client
+type Lease struct {
+	ID     uint64
+	Key    string
+	Start  uint64
+	End    uint64
+	Issued time.Time
+}
+
+// Stats are the lease statistics of a key
+type Stats struct {
+	// Number of leases issued
+	Leases uint64
+	// Number of indexes leased
+	Indexes uint64
+	// Number of leases outstanding when their stream ended abnormally (e.g. a client crash)
+	Abandoned uint64
+	// Number of indexes within abandoned leases, these indexes are never reissued
+	AbandonedIndexes uint64
+}
@@ -0,0 +1,637 @@
+package idg
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrJournalCorrupt is returned when a journal record fails its checksum
+	ErrJournalCorrupt = errors.Error("journal record is corrupt")
+)
+
+const (
+	// JournalExt is the file extension of the active journal, rotated journals are
+	// suffixed with a sequence number (e.g. orders.journal.000001)
+	JournalExt = ".journal"
+
+	// DefaultJournalMaxSize is the default size at which a journal is rotated
+	DefaultJournalMaxSize = 64 << 20
+
+	// Length of a record header, payload length (2), start (8), end (8), first (8) and last (8)
+	journalHeaderLen = 34
+	// Length of a record checksum
+	journalCRCLen = 4
+	// Maximum length of a record source
+	journalMaxSource = 1024
+)
+
+// JournalConfig configures the journal of a PIDG
+type JournalConfig struct {
+	// MaxSize is the size (in bytes) at which the journal is rotated, 0 results in
+	// DefaultJournalMaxSize and -1 disables rotation
+	MaxSize int64
+	// MaxFiles is the number of rotated journals to keep, the oldest journals are removed
+	// on rotation. 0 keeps all rotated journals
+	MaxFiles int
+	// CompactWindow will compact journals once they are rotated, see CompactJournal. 0 disables
+	// compaction. Compaction and pruning run in the background and do not block reservations
+	CompactWindow time.Duration
+	// Source identifies the process recording reservations, defaults to <hostname>:<pid>
+	Source string
+}
+
+// WithJournal will enable an append-only journal of the reservations of a PIDG. The journal
+// is stored alongside the persistent file as <key>.journal
+// Note: Reservations are journaled before they are persisted. When a generator is opened,
+// the index is rolled forward past the last journaled reservation
+func WithJournal(cfg JournalConfig) Option {
+	return func(o *opts) {
+		o.journal = &cfg
+	}
+}
+
+// JournalRecord is a journaled reservation of the indexes [Start, End)
+type JournalRecord struct {
+	Start uint64
+	End   uint64
+	// Time of the first and last reservation of the record, these are equal unless the
+	// record has been compacted
+	First time.Time
+	Last  time.Time
+	// Process (or node) which made the reservation
+	Source string
+}
+
+// ReadJournal will call fn for each record of the journal of the provided key, oldest first
+// Note: An incomplete record at the end of the active journal (e.g. a write in progress) is ignored
+func ReadJournal(dir, key string, fn func(r JournalRecord) error) (err error) {
+	if !isGeneratorKey(key) {
+		err = ErrInvalidGeneratorKey
+		return
+	}
+
+	var fps []string
+	if fps, err = journalFiles(dir, key); err != nil {
+		return
+	}
+
+	for i, fp := range fps {
+		active := i == len(fps)-1 && strings.HasSuffix(fp, JournalExt)
+		if err = readJournalFile(fp, active, fn); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// JournalBetween will return the records of the journal of the provided key which were
+// reserved within [start, end)
+func JournalBetween(dir, key string, start, end time.Time) (rs []JournalRecord, err error) {
+	// The key is validated by ReadJournal
+	err = ReadJournal(dir, key, func(r JournalRecord) error {
+		if r.Last.Before(start) || !r.First.Before(end) {
+			return nil
+		}
+
+		rs = append(rs, r)
+		return nil
+	})
+
+	return
+}
+
+// CompactJournal will compact the rotated journals of the provided key. Consecutive records
+// of the same source with contiguous ranges reserved within the window are merged
+// Note: The active journal is not compacted. ErrLocked is returned when the generator is open
+func CompactJournal(dir, key string, window time.Duration) (err error) {
+	if !isGeneratorKey(key) {
+		err = ErrInvalidGeneratorKey
+		return
+	}
+
+	var f *File
+	if f, err = OpenFile(filepath.Join(dir, key+FileExt)); err == nil {
+		defer f.Close()
+	} else if !os.IsNotExist(err) {
+		return
+	}
+
+	var fps []string
+	if fps, err = journalFiles(dir, key); err != nil {
+		return
+	}
+
+	for _, fp := range fps {
+		if strings.HasSuffix(fp, JournalExt) {
+			continue
+		}
+
+		if err = compactJournalFile(fp, window); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// openJournal will open the active journal of the provided key
+func openJournal(dir, key string, cfg JournalConfig) (j *journal, err error) {
+	var jj journal
+	jj.dir = dir
+	jj.key = key
+	jj.cfg = cfg
+	if jj.cfg.MaxSize == 0 {
+		jj.cfg.MaxSize = DefaultJournalMaxSize
+	}
+
+	if jj.cfg.Source == "" {
+		host, _ := os.Hostname()
+		jj.cfg.Source = host + ":" + strconv.Itoa(os.Getpid())
+	}
+
+	if len(jj.cfg.Source) > journalMaxSource {
+		jj.cfg.Source = jj.cfg.Source[:journalMaxSource]
+	}
+
+	if err = jj.open(); err != nil {
+		return
+	}
+
+	j = &jj
+	return
+}
+
+// journal is the append-only reservation journal of a PIDG
+type journal struct {
+	dir string
+	key string
+	cfg JournalConfig
+
+	f *os.File
+	// Size of the active journal
+	size int64
+	// Highest end of the journaled reservations
+	end uint64
+	// Write buffer
+	buf []byte
+
+	// Serializes the background compaction and pruning of rotated journals
+	mux sync.Mutex
+	wg  sync.WaitGroup
+	// Called with the errors of background compaction and pruning
+	onError func(error)
+}
+
+// open will open the active journal, truncating an incomplete trailing record
+func (j *journal) open() (err error) {
+	if j.f, err = os.OpenFile(j.activePath(), os.O_CREATE|os.O_RDWR, 0644); err != nil {
+		return
+	}
+
+	var valid int64
+	if valid, err = scanJournal(j.f, true, func(r JournalRecord) error {
+		if r.End > j.end {
+			j.end = r.End
+		}
+
+		return nil
+	}); err != nil {
+		j.f.Close()
+		return
+	}
+
+	// Remove an incomplete record left by a crash, records are appended at the end
+	if err = j.f.Truncate(valid); err != nil {
+		j.f.Close()
+		return
+	}
+
+	if _, err = j.f.Seek(valid, io.SeekStart); err != nil {
+		j.f.Close()
+		return
+	}
+
+	j.size = valid
+	if j.end == 0 {
+		// The active journal is empty, recover the end from the latest rotated journal
+		err = j.recoverRotated()
+	}
+
+	return
+}
+
+// recoverRotated will set the end of the journal from the latest non-empty rotated journal
+func (j *journal) recoverRotated() (err error) {
+	var fps []string
+	if fps, err = journalFiles(j.dir, j.key); err != nil {
+		return
+	}
+
+	for i := len(fps) - 1; i >= 0 && j.end == 0; i-- {
+		if strings.HasSuffix(fps[i], JournalExt) {
+			continue
+		}
+
+		if err = readJournalFile(fps[i], false, func(r JournalRecord) error {
+			if r.End > j.end {
+				j.end = r.End
+			}
+
+			return nil
+		}); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// append will append a reservation of [start, end) to the journal
+func (j *journal) append(start, end uint64, t time.Time, sync bool) (err error) {
+	if j.f == nil {
+		// A previous rotation failed to reopen the active journal
+		if err = j.reopen(nil); err != nil {
+			return
+		}
+	}
+
+	j.buf = appendJournalRecord(j.buf[:0], JournalRecord{Start: start, End: end, First: t, Last: t, Source: j.cfg.Source})
+	if _, err = j.f.Write(j.buf); err != nil {
+		// Remove a partially written record so following records remain readable
+		j.f.Truncate(j.size)
+		j.f.Seek(j.size, io.SeekStart)
+		return
+	}
+
+	j.size += int64(len(j.buf))
+	if end > j.end {
+		j.end = end
+	}
+
+	if sync {
+		err = j.f.Sync()
+	}
+
+	return
+}
+
+// needsRotation will return whether or not the active journal has reached its maximum size
+func (j *journal) needsRotation() bool {
+	return j.cfg.MaxSize > 0 && j.size >= j.cfg.MaxSize
+}
+
+// rotate will rotate the active journal, rotated journals are compacted and pruned as
+// configured in the background
+// Note: The active journal is reopened when the rotation fails so reservations can continue
+func (j *journal) rotate() (err error) {
+	var fps []string
+	if fps, err = journalFiles(j.dir, j.key); err != nil {
+		return
+	}
+
+	var seq int
+	for _, fp := range fps {
+		if n, ok := journalSeq(fp); ok && n > seq {
+			seq = n
+		}
+	}
+
+	err = j.f.Close()
+	j.f = nil
+	if err != nil {
+		return j.reopen(err)
+	}
+
+	active := j.activePath()
+	rotated := fmt.Sprintf("%s.%06d", active, seq+1)
+	if err = os.Rename(active, rotated); err != nil {
+		// Continue appending to the active journal
+		return j.reopen(err)
+	}
+
+	if err = j.reopen(nil); err != nil {
+		return
+	}
+
+	if j.cfg.CompactWindow > 0 || j.cfg.MaxFiles > 0 {
+		j.wg.Add(1)
+		go j.maintain(rotated)
+	}
+
+	return
+}
+
+// reopen will open the active journal for appending and return the provided error, the
+// error of opening the journal is returned when cause is nil
+func (j *journal) reopen(cause error) (err error) {
+	if j.f, err = os.OpenFile(j.activePath(), os.O_CREATE|os.O_RDWR, 0644); err != nil {
+		j.f = nil
+		return
+	}
+
+	if j.size, err = j.f.Seek(0, io.SeekEnd); err != nil {
+		j.f.Close()
+		j.f = nil
+		return
+	}
+
+	return cause
+}
+
+// activePath will return the path of the active journal
+func (j *journal) activePath() string {
+	return filepath.Join(j.dir, j.key+JournalExt)
+}
+
+// maintain will compact the provided rotated journal and prune the oldest rotated journals
+func (j *journal) maintain(rotated string) {
+	defer j.wg.Done()
+	j.mux.Lock()
+	defer j.mux.Unlock()
+
+	if err := j.compactAndPrune(rotated); err != nil && j.onError != nil {
+		j.onError(err)
+	}
+}
+
+// compactAndPrune will compact the provided rotated journal and prune the oldest rotated journals
+func (j *journal) compactAndPrune(rotated string) (err error) {
+	if j.cfg.CompactWindow > 0 {
+		if err = compactJournalFile(rotated, j.cfg.CompactWindow); err != nil {
+			return
+		}
+	}
+
+	if j.cfg.MaxFiles <= 0 {
+		return
+	}
+
+	var fps []string
+	if fps, err = journalFiles(j.dir, j.key); err != nil {
+		return
+	}
+
+	if len(fps) > 0 && strings.HasSuffix(fps[len(fps)-1], JournalExt) {
+		// The active journal is last
+		fps = fps[:len(fps)-1]
+	}
+
+	// Prune the oldest rotated journals
+	for ; len(fps) > j.cfg.MaxFiles; fps = fps[1:] {
+		if err = os.Remove(fps[0]); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// close will wait for background compaction and close the active journal
+func (j *journal) close() (err error) {
+	j.wg.Wait()
+	if j.f == nil {
+		return
+	}
+
+	return j.f.Close()
+}
+
+// journalFiles will return the journals of the provided key, the rotated journals in order
+// followed by the active journal (when it exists)
+func journalFiles(dir, key string) (fps []string, err error) {
+	var entries []os.DirEntry
+	if entries, err = os.ReadDir(dir); err != nil {
+		return
+	}
+
+	var active string
+	prefix := key + JournalExt
+	for _, e := range entries {
+		switch name := e.Name(); {
+		case e.IsDir():
+		case name == prefix:
+			active = filepath.Join(dir, name)
+		case strings.HasPrefix(name, prefix+"."):
+			if _, ok := journalSeq(name); ok {
+				fps = append(fps, filepath.Join(dir, name))
+			}
+		}
+	}
+
+	sortJournalFiles(fps)
+	if active != "" {
+		fps = append(fps, active)
+	}
+
+	return
+}
+
+// journalSeq will return the sequence number of a rotated journal
+func journalSeq(fp string) (seq int, ok bool) {
+	ext := filepath.Ext(fp)
+	if len(ext) < 2 {
+		return
+	}
+
+	var err error
+	seq, err = strconv.Atoi(ext[1:])
+	return seq, err == nil
+}
+
+// sortJournalFiles will sort rotated journals by sequence number
+func sortJournalFiles(fps []string) {
+	sort.Slice(fps, func(i, k int) bool {
+		a, _ := journalSeq(fps[i])
+		b, _ := journalSeq(fps[k])
+		return a < b
+	})
+}
+
+// readJournalFile will call fn for each record of a journal file
+func readJournalFile(fp string, active bool, fn func(r JournalRecord) error) (err error) {
+	var f *os.File
+	if f, err = os.Open(fp); err != nil {
+		return
+	}
+	defer f.Close()
+
+	_, err = scanJournal(f, active, fn)
+	return
+}
+
+// scanJournal will call fn for each record read from r and return the length of the valid records
+// Note: When tolerant is set, a torn trailing record is ignored rather than reported. A record is
+// torn when it is incomplete, when it is the last record and fails its checksum or when the
+// remainder of the journal is zero-filled (e.g. an extent allocated before a crash)
+func scanJournal(r io.Reader, tolerant bool, fn func(r JournalRecord) error) (valid int64, err error) {
+	br := bufio.NewReader(r)
+	buf := make([]byte, journalHeaderLen+journalMaxSource+journalCRCLen)
+	for {
+		if _, err = io.ReadFull(br, buf[:2]); err != nil {
+			break
+		}
+
+		n := int(binary.BigEndian.Uint16(buf[:2]))
+		if n < journalHeaderLen-2 || n > journalHeaderLen-2+journalMaxSource {
+			if tolerant && isZero(buf[:2]) && isZeroTail(br) {
+				return valid, nil
+			}
+
+			err = ErrJournalCorrupt
+			return
+		}
+
+		total := 2 + n + journalCRCLen
+		if _, err = io.ReadFull(br, buf[2:total]); err != nil {
+			break
+		}
+
+		var rec JournalRecord
+		if rec, err = decodeJournalRecord(buf[:total]); err != nil {
+			if tolerant && isZeroTail(br) {
+				return valid, nil
+			}
+
+			return
+		}
+
+		if err = fn(rec); err != nil {
+			return
+		}
+
+		valid += int64(total)
+	}
+
+	switch {
+	case err == io.EOF:
+		err = nil
+	case err == io.ErrUnexpectedEOF && tolerant:
+		err = nil
+	case err == io.ErrUnexpectedEOF:
+		err = ErrJournalCorrupt
+	}
+
+	return
+}
+
+// isZeroTail will return whether or not the remainder of r is empty or zero-filled
+func isZeroTail(r io.Reader) bool {
+	var buf [4096]byte
+	for {
+		n, err := r.Read(buf[:])
+		if !isZero(buf[:n]) {
+			return false
+		}
+
+		if err == io.EOF {
+			return true
+		} else if err != nil {
+			return false
+		}
+	}
+}
+
+// isZero will return whether or not every byte of b is zero
+func isZero(b []byte) bool {
+	for _, c := range b {
+		if c != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
+// appendJournalRecord will append the encoded record to dst
+func appendJournalRecord(dst []byte, r JournalRecord) []byte {
+	start := len(dst)
+	dst = binary.BigEndian.AppendUint16(dst, uint16(journalHeaderLen-2+len(r.Source)))
+	dst = binary.BigEndian.AppendUint64(dst, r.Start)
+	dst = binary.BigEndian.AppendUint64(dst, r.End)
+	dst = binary.BigEndian.AppendUint64(dst, uint64(r.First.UnixNano()))
+	dst = binary.BigEndian.AppendUint64(dst, uint64(r.Last.UnixNano()))
+	dst = append(dst, r.Source...)
+	return binary.BigEndian.AppendUint32(dst, crc32.Checksum(dst[start:], crcTable))
+}
+
+// decodeJournalRecord will decode an encoded record
+func decodeJournalRecord(b []byte) (r JournalRecord, err error) {
+	body := b[:len(b)-journalCRCLen]
+	if crc32.Checksum(body, crcTable) != binary.BigEndian.Uint32(b[len(body):]) {
+		err = ErrJournalCorrupt
+		return
+	}
+
+	r.Start = binary.BigEndian.Uint64(body[2:10])
+	r.End = binary.BigEndian.Uint64(body[10:18])
+	r.First = time.Unix(0, int64(binary.BigEndian.Uint64(body[18:26])))
+	r.Last = time.Unix(0, int64(binary.BigEndian.Uint64(body[26:34])))
+	r.Source = string(body[34:])
+	return
+}
+
+// compactJournalFile will compact a rotated journal file, see CompactJournal
+func compactJournalFile(fp string, window time.Duration) (err error) {
+	var (
+		buf    []byte
+		cur    JournalRecord
+		hasCur bool
+	)
+
+	if err = readJournalFile(fp, false, func(r JournalRecord) error {
+		if hasCur && r.Source == cur.Source && r.Start == cur.End && r.Last.Sub(cur.First) < window {
+			cur.End = r.End
+			cur.Last = r.Last
+			return nil
+		}
+
+		if hasCur {
+			buf = appendJournalRecord(buf, cur)
+		}
+
+		cur, hasCur = r, true
+		return nil
+	}); err != nil {
+		return
+	}
+
+	if hasCur {
+		buf = appendJournalRecord(buf, cur)
+	}
+
+	// Write the compacted journal to a temporary file and atomically replace the journal
+	tmp := fp + ".tmp"
+	var f *os.File
+	if f, err = os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644); err != nil {
+		return
+	}
+
+	if _, err = f.Write(buf); err == nil {
+		err = f.Sync()
+	}
+
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+
+	if err != nil {
+		os.Remove(tmp)
+		return
+	}
+
+	return os.Rename(tmp, fp)
+}
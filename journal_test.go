@@ -0,0 +1,300 @@
+package idg
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestJournal(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	before := time.Now()
+	p, err := NewPersistent("journal", "./test_data", WithJournal(JournalConfig{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 3; i++ {
+		if _, err = p.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err = p.Reserve(10); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var rs []JournalRecord
+	if rs, err = JournalBetween("./test_data", "journal", before, time.Now().Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][2]uint64{{0, 1}, {1, 2}, {2, 3}, {3, 13}}
+	if len(rs) != len(expected) {
+		t.Fatalf("invalid number of records, expected %d and received %d", len(expected), len(rs))
+	}
+
+	for i, r := range rs {
+		if r.Start != expected[i][0] || r.End != expected[i][1] || r.Source == "" || !r.First.Equal(r.Last) {
+			t.Fatalf("invalid record %d: %+v", i, r)
+		}
+	}
+
+	if rs, err = JournalBetween("./test_data", "journal", before.Add(-time.Hour), before); err != nil || len(rs) != 0 {
+		t.Fatalf("invalid records before the journal: %v / %v", rs, err)
+	}
+}
+
+func TestJournalRotation(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	cfg := JournalConfig{
+		// Each record is 42 bytes, the journal is rotated every 3 records
+		MaxSize:       100,
+		MaxFiles:      2,
+		CompactWindow: time.Hour,
+		Source:        "test",
+	}
+
+	p, err := NewPersistent("rotated", "./test_data", WithJournal(cfg))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 20; i++ {
+		if _, err = p.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var fps []string
+	if fps, err = journalFiles("./test_data", "rotated"); err != nil {
+		t.Fatal(err)
+	}
+
+	// Two rotated journals and the active journal
+	if len(fps) != 3 {
+		t.Fatalf("invalid journal files: %v", fps)
+	}
+
+	var rs []JournalRecord
+	if err = ReadJournal("./test_data", "rotated", func(r JournalRecord) error {
+		rs = append(rs, r)
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each rotated journal is compacted into a single record, followed by the active records
+	expected := [][2]uint64{{12, 15}, {15, 18}, {18, 19}, {19, 20}}
+	if len(rs) != len(expected) {
+		t.Fatalf("invalid records: %+v", rs)
+	}
+
+	for i, r := range rs {
+		if r.Start != expected[i][0] || r.End != expected[i][1] || r.Source != "test" {
+			t.Fatalf("invalid record %d: %+v", i, r)
+		}
+	}
+
+	// Rotated journals fail their checksum when corrupted
+	b, err := os.ReadFile(fps[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b[5] ^= 1
+	if err = os.WriteFile(fps[0], b, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ReadJournal("./test_data", "rotated", func(JournalRecord) error { return nil }); err != ErrJournalCorrupt {
+		t.Fatalf("invalid error, expected %v and received %v", ErrJournalCorrupt, err)
+	}
+}
+
+func TestJournalRecovery(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	ops := []Option{WithJournal(JournalConfig{Source: "test"})}
+	p, err := NewPersistent("recovery", "./test_data", ops...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = p.Reserve(100); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a crash after journaling and before persisting by moving the index backwards
+	var f *File
+	if f, err = OpenFile(filepath.Join("./test_data", "recovery"+FileExt)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = f.write(10); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+
+	// Simulate a torn write at the end of the journal
+	var jf *os.File
+	if jf, err = os.OpenFile(filepath.Join("./test_data", "recovery"+JournalExt), os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jf.Write([]byte{0, 40, 1, 2, 3})
+	jf.Close()
+
+	if p, err = NewPersistent("recovery", "./test_data", ops...); err != nil {
+		t.Fatal(err)
+	}
+
+	var id ID
+	if id, err = p.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = CompactJournal("./test_data", "recovery", time.Hour); err != ErrLocked {
+		t.Fatalf("invalid error, expected %v and received %v", ErrLocked, err)
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err = ReadJournal("./test_data", "recovery", func(JournalRecord) error {
+		n++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 2 {
+		t.Fatalf("invalid number of records, expected %d and received %d", 2, n)
+	}
+}
+
+func TestJournalTornTail(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	ops := []Option{WithJournal(JournalConfig{Source: "test"})}
+	tails := [][]byte{
+		// Full-length record which fails its checksum
+		appendJournalRecord(nil, JournalRecord{Start: 1, End: 2, Source: "test"})[:42],
+		// Zero-filled extent
+		make([]byte, 4096),
+	}
+
+	tails[0][41] ^= 1
+	for i, tail := range tails {
+		p, err := NewPersistent("torn", "./test_data", ops...)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		if _, err = p.Next(); err != nil {
+			t.Fatal(err)
+		}
+
+		if err = p.Close(); err != nil {
+			t.Fatal(err)
+		}
+
+		var jf *os.File
+		if jf, err = os.OpenFile(filepath.Join("./test_data", "torn"+JournalExt), os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+			t.Fatal(err)
+		}
+
+		jf.Write(tail)
+		jf.Close()
+		if p, err = NewPersistent("torn", "./test_data", ops...); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+
+		if err = p.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var n int
+	if err := ReadJournal("./test_data", "torn", func(JournalRecord) error {
+		n++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 2 {
+		t.Fatalf("invalid number of records, expected %d and received %d", 2, n)
+	}
+}
+
+func TestJournalRotationFailure(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	if err := os.MkdirAll(filepath.Join("./test_data", "failed"+JournalExt+".000001"), 0744); err != nil {
+		t.Fatal(err)
+	}
+
+	// The rotated journal name is taken by a directory, rotation fails on every attempt
+	p, err := NewPersistent("failed", "./test_data", WithJournal(JournalConfig{MaxSize: 100, Source: "test"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		if _, err = p.Next(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err = p.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var n int
+	if err = ReadJournal("./test_data", "failed", func(JournalRecord) error {
+		n++
+		return nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 10 {
+		t.Fatalf("invalid number of records, expected %d and received %d", 10, n)
+	}
+}
+
+func TestJournalInvalidKey(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	if err := ReadJournal("./test_data", "../journal", func(JournalRecord) error { return nil }); err != ErrInvalidGeneratorKey {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidGeneratorKey, err)
+	}
+
+	if _, err := JournalBetween("./test_data", "..", time.Time{}, time.Now()); err != ErrInvalidGeneratorKey {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidGeneratorKey, err)
+	}
+
+	if err := CompactJournal("./test_data", "journal/../../x", time.Hour); err != ErrInvalidGeneratorKey {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidGeneratorKey, err)
+	}
+
+	if _, err := NewPersistent("../journal", "./test_data"); err != ErrInvalidGeneratorKey {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidGeneratorKey, err)
+	}
+}
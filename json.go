@@ -0,0 +1,107 @@
+package idg
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+const (
+	// Maximum length of a raw value within a JSONError
+	maxJSONErrValueLen = 64
+)
+
+var (
+	// JSON null literal
+	jsonNull = []byte("null")
+	// Encode ID32 values as decimal strings of their numeric representation
+	jsonNumber32 bool
+)
+
+const (
+	// Minimum length of a decimal ID32 string, numeric representations of timestamps
+	// following 1970-07-14 (2^24 seconds) hold at least 17 digits. Every other built-in
+	// encoding of an ID32 is at most 16 characters
+	minDecimal32Len = 17
+	// Maximum length of a decimal ID32 string
+	maxDecimal32Len = 20
+)
+
+// SetJSONNumber32 will set whether or not ID32 values are encoded as the numeric
+// representation (see ID32.Uint64). The numeric representation exceeds 2^53 and is
+// encoded as a decimal string so precision is retained by every JSON decoder
+// Note: ID32 values are always accepted as encoded strings, decimal strings and numbers
+// when decoding. This is not thread-safe and is intended to be called during initialization
+func SetJSONNumber32(enabled bool) {
+	jsonNumber32 = enabled
+}
+
+// JSONError is returned when a JSON value cannot be decoded as an ID
+type JSONError struct {
+	// Type is the name of the ID type (e.g. "ID32")
+	Type string
+	// Value is the raw JSON value which failed to decode
+	Value string
+	// Err is the underlying error
+	Err error
+}
+
+// newJSONError will return a new JSONError for the provided raw value
+func newJSONError(typ string, in []byte, err error) *JSONError {
+	var e JSONError
+	e.Type = typ
+	if len(in) > maxJSONErrValueLen {
+		e.Value = string(in[:maxJSONErrValueLen]) + "..."
+	} else {
+		e.Value = string(in)
+	}
+
+	e.Err = err
+	return &e
+}
+
+// Error will return the error message
+func (e *JSONError) Error() string {
+	return "idg: cannot decode JSON value " + e.Value + " as " + e.Type + ": " + e.Err.Error()
+}
+
+// Unwrap will return the underlying error
+func (e *JSONError) Unwrap() error {
+	return e.Err
+}
+
+// isJSONNull will return whether or not the provided value is a JSON null
+func isJSONNull(in []byte) bool {
+	return bytes.Equal(bytes.TrimSpace(in), jsonNull)
+}
+
+// jsonString will return the contents of a JSON string
+// Note: Strings without escape sequences are returned without allocating
+func jsonString(in []byte) (str []byte, err error) {
+	in = bytes.TrimSpace(in)
+	if len(in) >= 2 && in[0] == '"' && in[len(in)-1] == '"' && bytes.IndexByte(in, '\\') == -1 {
+		return in[1 : len(in)-1], nil
+	}
+
+	var s string
+	if err = json.Unmarshal(in, &s); err != nil {
+		return
+	}
+
+	str = []byte(s)
+	return
+}
+
+// isDecimal32 will return whether or not the provided string is a decimal ID32 string
+func isDecimal32(in []byte) bool {
+	if len(in) < minDecimal32Len || len(in) > maxDecimal32Len {
+		return false
+	}
+
+	for _, c := range in {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,141 @@
+package idg
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestJSONNull(t *testing.T) {
+	var (
+		b   []byte
+		err error
+	)
+
+	var empty ID
+	if b, err = json.Marshal(&empty); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "null" {
+		t.Fatalf("invalid JSON, expected %s and received %s", "null", b)
+	}
+
+	var nilID *ID
+	if b, err = nilID.MarshalJSON(); err != nil {
+		t.Fatal(err)
+	} else if string(b) != "null" {
+		t.Fatalf("invalid JSON, expected %s and received %s", "null", b)
+	}
+
+	id := newID(1337, -1)
+	if err = json.Unmarshal([]byte("null"), &id); err != nil {
+		t.Fatal(err)
+	} else if !id.IsEmpty() {
+		t.Fatalf("ID is not empty: %v", id.Bytes())
+	}
+
+	var ts testStruct
+	if err = json.Unmarshal([]byte(`{"id":null}`), &ts); err != nil {
+		t.Fatal(err)
+	} else if ts.ID != nil {
+		t.Fatalf("ID is not nil: %v", ts.ID)
+	}
+}
+
+func TestJSONTolerant(t *testing.T) {
+	id := newID(1337, -1)
+	// Escaped characters, other encodings and UUID strings should all be accepted
+	inputs := []string{
+		`"` + id.String() + `"`,
+		`"\u0041` + id.String()[1:] + `"`,
+		`"` + id.Format(Hex) + `"`,
+		`"` + id.UUIDString() + `"`,
+	}
+
+	// The escaped input replaces the first character with A
+	escaped := id
+	escaped[0] &= 0x03
+
+	for i, in := range inputs {
+		var nid ID
+		if err := json.Unmarshal([]byte(in), &nid); err != nil {
+			t.Fatalf("error decoding %s: %v", in, err)
+		}
+
+		expected := id
+		if i == 1 {
+			expected = escaped
+		}
+
+		if nid != expected {
+			t.Fatalf("ID's do not match for %s: %v / %v", in, expected.Bytes(), nid.Bytes())
+		}
+	}
+}
+
+func TestJSONError(t *testing.T) {
+	var (
+		ts   testStruct
+		jerr *JSONError
+	)
+
+	err := json.Unmarshal([]byte(`{"id":"abc"}`), &ts)
+	if !errors.As(err, &jerr) {
+		t.Fatalf("invalid error, expected *JSONError and received %v", err)
+	}
+
+	if jerr.Type != "ID" || jerr.Value != `"abc"` {
+		t.Fatalf("invalid error values: %s / %s", jerr.Type, jerr.Value)
+	}
+
+	if err = json.Unmarshal([]byte(`{"id":true}`), &ts); !errors.As(err, &jerr) {
+		t.Fatalf("invalid error, expected *JSONError and received %v", err)
+	}
+
+	// The ID is left untouched when decoding fails
+	id := newID(1337, 1700000000)
+	nid := id
+	if err = nid.UnmarshalJSON([]byte(`"` + id.String()[:20] + `!!"`)); err == nil {
+		t.Fatal("expected an error")
+	} else if nid != id {
+		t.Fatalf("ID was modified by a failed decode: %v / %v", id.Bytes(), nid.Bytes())
+	}
+}
+
+func TestJSONNumber32(t *testing.T) {
+	var (
+		b   []byte
+		nid ID32
+		err error
+	)
+
+	SetJSONNumber32(true)
+	defer SetJSONNumber32(false)
+
+	id := newID32(1337, 1700000000)
+	if b, err = json.Marshal(&id); err != nil {
+		t.Fatal(err)
+	} else if string(b) != `"7301444403200001337"` {
+		t.Fatalf("invalid JSON, expected %s and received %s", `"7301444403200001337"`, b)
+	}
+
+	if err = json.Unmarshal(b, &nid); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	// Numbers should be accepted as well
+	nid = ID32{}
+	if err = json.Unmarshal([]byte("7301444403200001337"), &nid); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	// Strings should be accepted as well
+	if err = json.Unmarshal([]byte(`"`+id.String()+`"`), &nid); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+}
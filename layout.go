@@ -0,0 +1,27 @@
+package idg
+
+// Layout represents the byte layout of an ID
+type Layout uint8
+
+const (
+	// LayoutIndexFirst is the default layout. The first 8 bytes hold the index and
+	// the last 8 bytes hold the Unix timestamp (in seconds)
+	LayoutIndexFirst Layout = iota
+	// LayoutUUIDv7 is an RFC 9562 UUIDv7 compatible layout. The first 6 bytes hold the
+	// Unix timestamp (in milliseconds), followed by the version and variant bits with the
+	// index occupying the rand_a and rand_b space
+	LayoutUUIDv7
+)
+
+// String will return a string representation of a Layout
+func (l Layout) String() string {
+	switch l {
+	case LayoutIndexFirst:
+		return "index-first"
+	case LayoutUUIDv7:
+		return "uuidv7"
+
+	default:
+		return "invalid"
+	}
+}
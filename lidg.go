@@ -0,0 +1,188 @@
+package idg
+
+import (
+	"io"
+	"log/slog"
+	"sync"
+
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidBlockSize is returned when a block size of zero is provided
+	ErrInvalidBlockSize = errors.Error("invalid block size")
+	// ErrIndexExhausted is returned when a block would wrap past the last index, the
+	// following indexes would reissue indexes from zero
+	ErrIndexExhausted = errors.Error("index space exhausted")
+)
+
+// Ensure LIDG implements Generator
+var _ Generator = &LIDG{}
+
+// BlockSource is implemented by sources of contiguous index blocks, such as PIDG or
+// remote lease clients (e.g. idgrpc.Client)
+type BlockSource interface {
+	// Reserve will reserve a block of n indexes and return the first index of the block
+	Reserve(n uint64) (start uint64, err error)
+}
+
+// NewLeased will return a new ID generator which issues indexes from blocks of the provided size
+// Note: The next block is reserved in the background once half of the current block has been issued
+func NewLeased(src BlockSource, size uint64, ops ...Option) (l *LIDG, err error) {
+	if size == 0 {
+		err = ErrInvalidBlockSize
+		return
+	}
+
+	var lidg LIDG
+	lidg.opts.apply(ops)
+	lidg.src = src
+	lidg.size = size
+	// Blocks are reserved on first use, the index is not known until the first block
+	lidg.opened(0, slog.Uint64("size", size))
+	l = &lidg
+	return
+}
+
+// LIDG is a leased block ID generator
+// Note: Indexes of unused blocks are not reissued, this results in gaps when a generator
+// is closed before its blocks are exhausted
+type LIDG struct {
+	opts
+
+	mux sync.Mutex
+	// Source of index blocks
+	src BlockSource
+	// Size of blocks
+	size uint64
+	// Current and prefetched blocks
+	cur  block
+	next block
+	// Closed when the in-flight prefetch completes, nil when no prefetch is in-flight
+	pending chan struct{}
+	// Error encountered by the last prefetch
+	err error
+}
+
+// take will take the next index
+func (l *LIDG) take() (idx uint64, err error) {
+	l.mux.Lock()
+	defer l.mux.Unlock()
+
+	for l.cur.isEmpty() {
+		if !l.next.isEmpty() {
+			l.cur, l.next = l.next, block{}
+			break
+		}
+
+		if pending := l.pending; pending != nil {
+			// Wait for the in-flight prefetch to complete
+			l.mux.Unlock()
+			<-pending
+			l.mux.Lock()
+			continue
+		}
+
+		if err = l.err; err != nil {
+			// Report the prefetch error once, the following call will try again
+			l.err = nil
+			return
+		}
+
+		// Nothing is prefetched, reserve the block synchronously
+		if l.cur, err = l.reserve(); err != nil {
+			return
+		}
+	}
+
+	idx = l.cur.start
+	l.cur.start++
+
+	if l.cur.len() <= l.size/2 && l.next.isEmpty() && l.pending == nil && l.err == nil {
+		l.prefetch()
+	}
+
+	return
+}
+
+// reserve will reserve a new block from the source
+func (l *LIDG) reserve() (b block, err error) {
+	if b.start, err = l.src.Reserve(l.size); err != nil {
+		return
+	}
+
+	b.end = b.start + l.size
+	l.refilled(l.size)
+	return
+}
+
+// prefetch will reserve the next block in the background
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (l *LIDG) prefetch() {
+	pending := make(chan struct{})
+	l.pending = pending
+
+	go func() {
+		b, err := l.reserve()
+		l.mux.Lock()
+		l.next, l.err = b, err
+		l.pending = nil
+		l.mux.Unlock()
+		close(pending)
+	}()
+}
+
+// Next will return the next id
+func (l *LIDG) Next() (id ID, err error) {
+	var idx uint64
+	if idx, err = l.take(); err != nil {
+		return
+	}
+
+	id = l.newID(idx)
+	return
+}
+
+// Next32 will return the next 32-bit id
+func (l *LIDG) Next32() (id ID32, err error) {
+	var idx uint64
+	if idx, err = l.take(); err != nil {
+		return
+	}
+
+	id = l.newID32(idx)
+	return
+}
+
+// Close will wait for any in-flight prefetch and close the source if it implements io.Closer
+func (l *LIDG) Close() (err error) {
+	l.mux.Lock()
+	pending := l.pending
+	l.mux.Unlock()
+
+	if pending != nil {
+		<-pending
+	}
+
+	if c, ok := l.src.(io.Closer); ok {
+		err = c.Close()
+	}
+
+	l.closed(err)
+	return
+}
+
+// block is a contiguous index block of [start, end)
+type block struct {
+	start uint64
+	end   uint64
+}
+
+func (b *block) len() uint64 {
+	return b.end - b.start
+}
+
+func (b *block) isEmpty() bool {
+	return b.start == b.end
+}
@@ -0,0 +1,225 @@
+package idg
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PathDNA/turtleDB"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+func TestPIDGReserve(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	pidg, err := NewPersistent("reserve", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var start uint64
+	if start, err = pidg.Reserve(10); err != nil {
+		t.Fatal(err)
+	} else if start != 0 {
+		t.Fatalf("invalid start, expected %d and received %d", 0, start)
+	}
+
+	if _, err = pidg.Reserve(0); err != ErrInvalidBlockSize {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidBlockSize, err)
+	}
+
+	if err = pidg.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The reserved block must not be reissued after reopening
+	if pidg, err = NewPersistent("reserve", "./test_data"); err != nil {
+		t.Fatal(err)
+	}
+	defer pidg.Close()
+
+	var id ID
+	if id, err = pidg.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 10); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestReserveExhausted(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	pidg, err := NewPersistent("exhausted", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = pidg.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var f *File
+	if f, err = OpenFile(filepath.Join("./test_data", "exhausted"+FileExt)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = f.Bump(math.MaxUint64 - 5); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if pidg, err = NewPersistent("exhausted", "./test_data"); err != nil {
+		t.Fatal(err)
+	}
+	defer pidg.Close()
+
+	// Blocks which would wrap past the last index are refused and the index is unchanged
+	if _, err = pidg.Reserve(10); err != ErrIndexExhausted {
+		t.Fatalf("invalid error, expected %v and received %v", ErrIndexExhausted, err)
+	}
+
+	var start uint64
+	if start, err = pidg.Reserve(5); err != nil || start != math.MaxUint64-5 {
+		t.Fatalf("invalid start, expected %d and received %d (%v)", uint64(math.MaxUint64-5), start, err)
+	}
+
+	fm := turtleDB.FuncsMap{}
+	tidg := NewTIDG("exhausted", fm)
+	var db turtleDB.DB
+	if db, err = turtleDB.New("exhausted", "./test_data/tidg", fm); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	snap := `{"version":1,"indexes":{"exhausted":18446744073709551610}}`
+	if err = db.Update(func(txn turtleDB.Txn) (err error) {
+		if err = tidg.Restore(txn, strings.NewReader(snap)); err != nil {
+			return
+		}
+
+		_, err = tidg.Reserve(txn, 10)
+		return
+	}); err != ErrIndexExhausted {
+		t.Fatalf("invalid error, expected %v and received %v", ErrIndexExhausted, err)
+	}
+}
+
+func TestLIDG(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	pidg, err := NewPersistent("leased", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var l *LIDG
+	if l, err = NewLeased(pidg, 16); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		wg   sync.WaitGroup
+		mux  sync.Mutex
+		seen = make(map[uint64]struct{})
+	)
+
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				id, err := l.Next()
+				if err != nil {
+					t.Error(err)
+					return
+				}
+
+				idx, _ := id.Index()
+				mux.Lock()
+				seen[idx] = struct{}{}
+				mux.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+	if len(seen) != 800 {
+		t.Fatalf("invalid number of unique indexes, expected %d and received %d", 800, len(seen))
+	}
+
+	if err = l.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Reopen and ensure no leased index is reissued
+	if pidg, err = NewPersistent("leased", "./test_data"); err != nil {
+		t.Fatal(err)
+	}
+	defer pidg.Close()
+
+	var id ID
+	if id, err = pidg.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	idx, _ := id.Index()
+	if _, ok := seen[idx]; ok || idx < 800 {
+		t.Fatalf("index %d was reissued", idx)
+	}
+}
+
+func TestLIDGError(t *testing.T) {
+	errTest := errors.Error("test error")
+	src := &testSource{err: errTest}
+	l, err := NewLeased(src, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = l.Next(); err != errTest {
+		t.Fatalf("invalid error, expected %v and received %v", errTest, err)
+	}
+
+	src.setErr(nil)
+	var id ID
+	if id, err = l.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = NewLeased(src, 0); err != ErrInvalidBlockSize {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidBlockSize, err)
+	}
+}
+
+type testSource struct {
+	mux sync.Mutex
+	idx uint64
+	err error
+}
+
+func (s *testSource) setErr(err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	s.err = err
+}
+
+func (s *testSource) Reserve(n uint64) (start uint64, err error) {
+	s.mux.Lock()
+	defer s.mux.Unlock()
+	if err = s.err; err != nil {
+		return
+	}
+
+	start = s.idx
+	s.idx += n
+	return
+}
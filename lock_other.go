@@ -0,0 +1,10 @@
+//go:build !unix
+
+package idg
+
+import "os"
+
+// lockFile is a no-op, file locking is not supported on this platform
+func lockFile(f *os.File) (err error) {
+	return
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package idg
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile will acquire an exclusive, non-blocking lock of the provided file
+// Note: The lock is released when the file is closed
+func lockFile(f *os.File) (err error) {
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err == syscall.EWOULDBLOCK {
+		err = ErrLocked
+	}
+
+	return
+}
@@ -0,0 +1,116 @@
+package idg
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Metrics receives generator events, see the idgprom package for a Prometheus implementation
+// Note: Implementations must be safe for concurrent use and should not block
+type Metrics interface {
+	// Issued is called when n IDs have been issued by the generator of the provided key
+	Issued(key string, n uint64)
+	// Persisted is called after an index has been persisted, err is set when persisting failed
+	Persisted(key string, d time.Duration, err error)
+	// SyncFailed is called when syncing a persistent file to disk fails
+	SyncFailed(key string, err error)
+	// Refilled is called when a leased generator has reserved a block of n indexes
+	Refilled(key string, n uint64)
+	// ClockRegressed is called when the clock has moved backwards by d
+	ClockRegressed(key string, d time.Duration)
+}
+
+// WithMetrics will set the metrics of a generator
+// Note: PIDG and TIDG report their key, other generators report the key set by WithKey
+func WithMetrics(m Metrics) Option {
+	return func(o *opts) {
+		o.metrics = &metrics{m: m}
+	}
+}
+
+// WithKey will set the key a generator reports to its metrics, hooks and logger
+// Note: This is not utilized by PIDG and TIDG, which report their own key
+func WithKey(key string) Option {
+	return func(o *opts) {
+		o.key = key
+	}
+}
+
+// metrics is the metrics state of a generator
+type metrics struct {
+	m Metrics
+
+	mux sync.Mutex
+	// Unix time (in nanoseconds) of the latest ID, utilized to detect clock regressions
+	last int64
+}
+
+// observe will return the current time and the duration the clock has moved backwards
+// since the latest observed time, zero when the clock has not regressed
+// Note: The time is read while holding the lock, times read concurrently outside of the
+// lock may be observed out of order and would be reported as false regressions
+func (m *metrics) observe() (now time.Time, d time.Duration) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	now = time.Now()
+	if ns := now.UnixNano(); ns < m.last {
+		d = time.Duration(m.last - ns)
+	} else {
+		m.last = ns
+	}
+
+	return
+}
+
+// now will return the current time, reporting when the clock has moved backwards
+// Note: Metrics only observe the time, the time is the same regardless of metrics
+func (o *opts) now() (now time.Time) {
+	if o.metrics == nil {
+		return time.Now()
+	}
+
+	var d time.Duration
+	if now, d = o.metrics.observe(); d > 0 {
+		o.clockRegressed(d)
+	}
+
+	return
+}
+
+// issued will report n issued IDs
+func (o *opts) issued(n uint64) {
+	if o.metrics != nil {
+		o.metrics.m.Issued(o.key, n)
+	}
+}
+
+// persisted will report a persist which started at the provided time
+func (o *opts) persisted(start time.Time, err error) {
+	if o.metrics != nil {
+		o.metrics.m.Persisted(o.key, time.Since(start), err)
+	}
+}
+
+// syncFailed will report a failed sync
+func (o *opts) syncFailed(err error) {
+	if o.metrics != nil {
+		o.metrics.m.SyncFailed(o.key, err)
+	}
+}
+
+// refilled will report a reserved block of n indexes
+func (o *opts) refilled(n uint64) {
+	if o.metrics != nil {
+		o.metrics.m.Refilled(o.key, n)
+	}
+}
+
+// clockRegressed will report a clock regression of d
+func (o *opts) clockRegressed(d time.Duration) {
+	if o.metrics != nil {
+		o.metrics.m.ClockRegressed(o.key, d)
+	}
+
+	o.log(slog.LevelWarn, "idg: clock regression", slog.Duration("duration", d))
+}
@@ -0,0 +1,131 @@
+package idg
+
+import (
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PathDNA/turtleDB"
+)
+
+func TestMetrics(t *testing.T) {
+	m := &testMetrics{issued: make(map[string]uint64)}
+	gen := New(0, WithMetrics(m), WithKey("idg"))
+	gen.Next()
+	gen.Next32()
+
+	sidg, err := NewSnowflake(1, TwitterSnowflake, WithMetrics(m), WithKey("sidg"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = sidg.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.issued["idg"] != 2 || m.issued["sidg"] != 1 {
+		t.Fatalf("invalid issued counts: %v", m.issued)
+	}
+
+	// Simulate the clock moving backwards
+	gen.metrics.last = time.Now().Add(time.Hour).UnixNano()
+	gen.Next()
+	if m.regressions != 1 {
+		t.Fatalf("invalid number of clock regressions, expected %d and received %d", 1, m.regressions)
+	}
+}
+
+func TestMetricsParallel(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	m := &testMetrics{issued: make(map[string]uint64)}
+	gen := New(0, WithMetrics(m), WithKey("idg"))
+	pidg, err := NewPersistent("metrics", "./test_data", WithMetrics(m))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pidg.Close()
+
+	// IDs are issued concurrently by lock-free and locked generators, the clock does not
+	// move backwards so no regressions should be reported
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10000; j++ {
+				gen.Next()
+				if _, err := pidg.Next(); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.regressions != 0 {
+		t.Fatalf("invalid number of clock regressions, expected %d and received %d", 0, m.regressions)
+	}
+}
+
+func TestMetricsTIDG(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	m := &testMetrics{issued: make(map[string]uint64)}
+	fm := turtleDB.FuncsMap{}
+	tidg := NewTIDG("tidg", fm, WithMetrics(m))
+	db, err := turtleDB.New("metrics_test", "./test_data", fm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err = db.Update(func(txn turtleDB.Txn) (err error) {
+		if _, err = tidg.Next(txn); err != nil {
+			return
+		}
+
+		_, err = tidg.Reserve(txn, 10)
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	if m.issued["tidg"] != 1 || m.persisted["tidg"] != 2 {
+		t.Fatalf("invalid counts: %v / %v", m.issued, m.persisted)
+	}
+}
+
+type testMetrics struct {
+	mux         sync.Mutex
+	issued      map[string]uint64
+	persisted   map[string]int
+	regressions int
+}
+
+func (m *testMetrics) Issued(key string, n uint64) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.issued[key] += n
+}
+
+func (m *testMetrics) Persisted(key string, d time.Duration, err error) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	if m.persisted == nil {
+		m.persisted = make(map[string]int)
+	}
+
+	m.persisted[key]++
+}
+
+func (m *testMetrics) SyncFailed(key string, err error) {}
+func (m *testMetrics) Refilled(key string, n uint64)    {}
+
+func (m *testMetrics) ClockRegressed(key string, d time.Duration) {
+	m.mux.Lock()
+	defer m.mux.Unlock()
+	m.regressions++
+}
@@ -0,0 +1,66 @@
+package idg
+
+import (
+	"github.com/itsmontoya/mum"
+)
+
+// MarshalMum is a mum encoding helper func
+// Note: The ID is written as two uint64 values, which round-trip the raw bytes
+func (id *ID) MarshalMum(enc *mum.Encoder) (err error) {
+	// Helper for binary decoding
+	var br mum.BinaryReader
+	var v uint64
+	for i := 0; i < len(id); i += 8 {
+		if v, err = br.Uint64(id[i : i+8]); err != nil {
+			return
+		}
+
+		if err = enc.Uint64(v); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// UnmarshalMum is a mum decoding helper func
+func (id *ID) UnmarshalMum(dec *mum.Decoder) (err error) {
+	// Helper for binary encoding
+	var bw mum.BinaryWriter
+	var v uint64
+	for i := 0; i < len(id); i += 8 {
+		if v, err = dec.Uint64(); err != nil {
+			return
+		}
+
+		copy(id[i:i+8], bw.Uint64(v))
+	}
+
+	return
+}
+
+// MarshalMum is a mum encoding helper func
+// Note: The ID32 is written as a single uint64 value, which round-trips the raw bytes
+func (id *ID32) MarshalMum(enc *mum.Encoder) (err error) {
+	// Helper for binary decoding
+	var br mum.BinaryReader
+	var v uint64
+	if v, err = br.Uint64(id[:]); err != nil {
+		return
+	}
+
+	return enc.Uint64(v)
+}
+
+// UnmarshalMum is a mum decoding helper func
+func (id *ID32) UnmarshalMum(dec *mum.Decoder) (err error) {
+	// Helper for binary encoding
+	var bw mum.BinaryWriter
+	var v uint64
+	if v, err = dec.Uint64(); err != nil {
+		return
+	}
+
+	copy(id[:], bw.Uint64(v))
+	return
+}
@@ -0,0 +1,57 @@
+package idg
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/itsmontoya/mum"
+)
+
+// Ensure IDs implement the mum encodee and decodee interfaces
+var (
+	_ mum.Encodee = &ID{}
+	_ mum.Decodee = &ID{}
+	_ mum.Encodee = &ID32{}
+	_ mum.Decodee = &ID32{}
+)
+
+func TestMum(t *testing.T) {
+	var (
+		buf   bytes.Buffer
+		nid   ID
+		nid32 ID32
+		err   error
+	)
+
+	id := newID(1337, -1)
+	id32 := newID32(1337, -1)
+	enc := mum.NewEncoder(&buf)
+	if err = enc.Encode(&id); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = enc.Encode(&id32); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() != len(id)+len(id32) {
+		t.Fatalf("invalid length, expected %d and received %d", len(id)+len(id32), buf.Len())
+	}
+
+	dec := mum.NewDecoder(&buf)
+	if err = dec.Decode(&nid); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = dec.Decode(&nid32); err != nil {
+		t.Fatal(err)
+	}
+
+	if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	if nid32 != id32 {
+		t.Fatalf("ID's do not match: %v / %v", id32.Bytes(), nid32.Bytes())
+	}
+}
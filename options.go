@@ -0,0 +1,139 @@
+package idg
+
+import (
+	"log/slog"
+	"math"
+)
+
+// Option is used to configure a generator
+type Option func(*opts)
+
+// WithLayout will set the layout of the IDs produced by a generator
+// Note: This only affects 16 byte IDs, ID32 values are not affected
+func WithLayout(l Layout) Option {
+	return func(o *opts) {
+		o.layout = l
+	}
+}
+
+// WithEncoding will set the encoding utilized by the Format and Parse methods of a generator
+// Note: ID.String and Parse continue to utilize the encoding set by SetEncoding
+func WithEncoding(enc Encoding) Option {
+	return func(o *opts) {
+		o.enc = enc
+	}
+}
+
+// opts are the configurable values shared by the generators
+type opts struct {
+	// Layout of generated IDs
+	layout Layout
+	// Text encoding of generated IDs
+	enc Encoding
+	// Time to index checkpoint log
+	checkpoints *Checkpoints
+	// Key reported to metrics
+	key string
+	// Metrics state, nil when metrics are not set
+	metrics *metrics
+	// Sync persistent files after each write
+	sync bool
+	// Lifecycle hooks
+	hooks Hooks
+	// Structured logger
+	logger *slog.Logger
+	// Tracer, nil when tracing is disabled
+	tracer Tracer
+	// Journal configuration, nil when journaling is disabled
+	journal *JournalConfig
+	// Maximum number of open generators of a directory registry, zero for the default
+	maxOpen int
+}
+
+// apply will apply the provided options
+func (o *opts) apply(ops []Option) {
+	for _, fn := range ops {
+		fn(o)
+	}
+}
+
+// WithSync will sync persistent files to disk after each write
+// Note: This trades generation throughput for durability across power loss
+func WithSync() Option {
+	return func(o *opts) {
+		o.sync = true
+	}
+}
+
+// blockStart will return the start of a block of n indexes at or after the provided start
+// Note: Index-first generators skip indexes which may be mistaken for UUIDv7 IDs, see isUUIDv7
+func (o *opts) blockStart(start, n uint64) uint64 {
+	if o.layout != LayoutIndexFirst {
+		return start
+	}
+
+	return skipAmbiguous(start, n)
+}
+
+// newID will return a new ID with the provided index and a current timestamp
+func (o *opts) newID(idx uint64) (id ID) {
+	if o.checkpoints != nil {
+		o.checkpoints.record(idx)
+	}
+
+	if idx == math.MaxUint64 {
+		// The index space has been exhausted, the following index wraps to zero
+		o.overflowed(idx)
+	}
+
+	id = Compose(idx, o.now(), o.layout)
+	o.issued(1)
+	return
+}
+
+// newID32 will return a new ID32 with the provided index and a current timestamp
+func (o *opts) newID32(idx uint64) (id ID32) {
+	if o.checkpoints != nil {
+		o.checkpoints.record(idx)
+	}
+
+	if idx > math.MaxUint32 {
+		// The index does not fit within an ID32 and is truncated
+		o.overflowed(idx)
+	}
+
+	id = Compose32(uint32(idx), o.now())
+	o.issued(1)
+	return
+}
+
+// IDOf will return the ID of an index reserved from the generator (e.g. utilizing Reserve)
+// with a current timestamp and the layout of the generator
+func (o *opts) IDOf(idx uint64) (id ID) {
+	return o.newID(idx)
+}
+
+// ID32Of will return the 32-bit ID of an index reserved from the generator (e.g. utilizing
+// Reserve) with a current timestamp
+func (o *opts) ID32Of(idx uint64) (id ID32) {
+	return o.newID32(idx)
+}
+
+// Encoding will return the text encoding of the generator
+func (o *opts) Encoding() (enc Encoding) {
+	if o.enc == nil {
+		return idEnc
+	}
+
+	return o.enc
+}
+
+// Format will return the string representation of an ID utilizing the encoding of the generator
+func (o *opts) Format(id ID) (out string) {
+	return encode(o.Encoding(), id[:])
+}
+
+// Parse will parse a string id utilizing the encoding of the generator
+func (o *opts) Parse(in string) (id ID, err error) {
+	return ParseWith(o.Encoding(), in)
+}
@@ -1,36 +1,97 @@
 package idg
 
 import (
-	"io"
+	"context"
+	"log/slog"
 	"os"
 	"path"
+	"strings"
+	"time"
 
 	"github.com/PathDNA/atoms"
-	"github.com/itsmontoya/mum"
+	"github.com/missionMeteora/toolkit/errors"
 )
 
+const (
+	// ErrInvalidGeneratorKey is returned when a persistent generator key would escape its directory
+	ErrInvalidGeneratorKey = errors.Error("invalid generator key, keys must not contain path separators or be \"..\"")
+)
+
+// Ensure PIDG implements Generator and BlockSource
+var (
+	_ Generator   = &PIDG{}
+	_ BlockSource = &PIDG{}
+)
+
+// IsValidKey will return whether or not a key is safe to use as a persistent generator key
+// Note: Keys are utilized as filenames, so keys must be 1 to 64 alphanumeric, dash or
+// underscore characters
+func IsValidKey(key string) bool {
+	if len(key) == 0 || len(key) > 64 {
+		return false
+	}
+
+	for _, c := range []byte(key) {
+		switch {
+		case c >= 'a' && c <= 'z':
+		case c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9':
+		case c == '-' || c == '_':
+
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// isGeneratorKey will return whether or not a key stays within its directory when utilized
+// as a filename
+// Note: This is more permissive than IsValidKey so existing generator files remain usable,
+// keys from untrusted sources should be checked with IsValidKey
+func isGeneratorKey(key string) bool {
+	return key != ".." && !strings.ContainsAny(key, `/\`)
+}
+
 //NewPersistent will return a new ID generator
-func NewPersistent(key, dir string) (pidg *PIDG, err error) {
+// Note: ErrInvalidGeneratorKey is returned when the key contains a path separator or is ".."
+func NewPersistent(key, dir string, ops ...Option) (pidg *PIDG, err error) {
+	if !isGeneratorKey(key) {
+		err = ErrInvalidGeneratorKey
+		return
+	}
+
 	var p PIDG
+	p.opts.apply(ops)
+	p.key = key
 	// Set file
 	if err = p.setFile(key, dir); err != nil {
+		p.log(slog.LevelError, "idg: error opening generator", slog.Any("error", err))
 		return
 	}
-	// Set encoder
-	p.enc = mum.NewEncoder(p.pf)
+
+	if err = p.setJournal(key, dir); err != nil {
+		p.f.Close()
+		p.log(slog.LevelError, "idg: error opening journal", slog.Any("error", err))
+		return
+	}
+
+	p.opened(p.idx, slog.String("format", p.f.Format().String()))
+
 	pidg = &p
 	return
 }
 
 // PIDG is an non-persistent atomic ID generator
 type PIDG struct {
+	opts
+
 	mux atoms.Mux
-	// Helper for binary encoding
-	bw mum.BinaryWriter
 	// Persistance file
-	pf *os.File
-	// Encoder writer
-	enc *mum.Encoder
+	f *File
+	// Reservation journal, nil when journaling is disabled
+	j *journal
 	// Current index
 	idx uint64
 }
@@ -43,87 +104,180 @@ func (p *PIDG) setFile(key, dir string) (err error) {
 	}
 
 	// Filepath
-	fp := path.Join(dir, key+".idg")
-	// Open (or create) file with read/write functionality
-	if p.pf, err = os.OpenFile(fp, os.O_CREATE|os.O_RDWR, 0644); err != nil {
+	fp := path.Join(dir, key+FileExt)
+	// Open (or create) and lock the file, legacy files are read and written in the
+	// legacy format until they are migrated (see File.Migrate)
+	if p.f, err = openFile(fp, os.O_CREATE); err != nil {
+		return
+	}
+	// Current index is the NEXT index following the last persisted value
+	p.idx = p.f.Index()
+	return
+}
+
+// setJournal will open the reservation journal when journaling is enabled
+func (p *PIDG) setJournal(key, dir string) (err error) {
+	if p.opts.journal == nil {
 		return
 	}
-	// Create a temporary decoder to read initial value
-	dec := mum.NewDecoder(p.pf)
-	var idx uint64
-	// Decode a uint64 value from the file
-	if idx, err = dec.Uint64(); err != nil {
-		if err == io.EOF {
-			// io.EOF means we do not yet have any index data saved, our default index of 0 is
-			// our intended value, error does not need to be reported
-			err = nil
-		}
 
+	if p.j, err = openJournal(dir, key, *p.opts.journal); err != nil {
 		return
 	}
-	// Current index would be the NEXT index following the last persisted value
-	p.idx = idx + 1
+
+	p.j.onError = func(err error) {
+		p.log(slog.LevelError, "idg: error compacting journal", slog.Any("error", err))
+	}
+
+	if p.j.end > p.idx {
+		// A reservation was journaled but not persisted (e.g. a crash), roll the index forward
+		p.log(slog.LevelWarn, "idg: index rolled forward to the journal", slog.Uint64("persisted", p.idx), slog.Uint64("index", p.j.end))
+		p.idx = p.j.end
+	}
+
 	return
 }
 
-// persist will store the current index to disk
+// persist will store the next index to disk following a reservation of n indexes
 // Note: This is NOT thread-safe, please ensure locking is
 // handled by the calling func
-func (p *PIDG) persist() (err error) {
-	if _, err = p.pf.Seek(0, io.SeekStart); err != nil {
-		return
+func (p *PIDG) persist(ctx context.Context, next, n uint64) (err error) {
+	_, span := p.startSpan(ctx, spanPersist, backendFile, n)
+	defer func() { endSpan(span, err) }()
+
+	var start time.Time
+	if p.metrics != nil {
+		start = time.Now()
+	}
+
+	if err = p.f.write(next); err == nil && p.sync {
+		if err = p.f.f.Sync(); err != nil {
+			p.syncFailed(err)
+		}
 	}
 
-	return p.enc.Uint64(p.idx)
+	p.persisted(start, err)
+	if err != nil {
+		p.persistFailed(next, err)
+	}
+
+	return
 }
 
-// Next will return the next id
-func (p *PIDG) Next() (id ID, err error) {
-	var idx uint64
+// reserve will reserve and persist a block of n indexes
+func (p *PIDG) reserve(ctx context.Context, n uint64) (start uint64, err error) {
+	ctx, span := p.startSpan(ctx, spanReserve, backendFile, n)
 	p.mux.Update(func() {
-		idx = p.idx
-		// Perist value to disk
-		if err = p.persist(); err != nil {
+		if start = p.blockStart(p.idx, n); start+n < start {
+			p.overflowed(start)
+			err = ErrIndexExhausted
+			return
+		}
+
+		if p.j != nil {
+			// Journal the reservation ahead of persisting it
+			if err = p.appendJournal(start, n); err != nil {
+				return
+			}
+			// A journaled block is never reissued, even when persisting fails
+			p.idx = start + n
+		}
+		// Perist the index following the end of the block to disk
+		if err = p.persist(ctx, start+n, n); err != nil {
 			return
 		}
-		// Increment index value
-		p.idx++
+		// Increment index value past the end of the block
+		p.idx = start + n
 	})
-	// Break early if error exists
-	if err != nil {
+
+	endSpan(span, err)
+	return
+}
+
+// appendJournal will append a reservation of n indexes to the journal, rotating the journal as needed
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (p *PIDG) appendJournal(start, n uint64) (err error) {
+	if err = p.j.append(start, start+n, time.Now(), p.sync); err != nil {
+		p.persistFailed(start+n, err)
+		return
+	}
+
+	if !p.j.needsRotation() {
+		return
+	}
+
+	// Rotation failures do not fail the reservation, the active journal is reopened and
+	// rotation is attempted again following the next append. Compaction runs in the background
+	if rerr := p.j.rotate(); rerr != nil {
+		p.log(slog.LevelError, "idg: error rotating journal", slog.Any("error", rerr))
+	}
+
+	return
+}
+
+// Next will return the next id
+func (p *PIDG) Next() (id ID, err error) {
+	return p.NextContext(context.Background())
+}
+
+// NextContext will return the next id, spans are parented to the provided context
+func (p *PIDG) NextContext(ctx context.Context) (id ID, err error) {
+	var idx uint64
+	if idx, err = p.reserve(ctx, 1); err != nil {
 		return
 	}
 	// Set id with the retrieved index (utilizing a current timestamp)
-	id = newID(idx, -1)
+	id = p.newID(idx)
 	return
 }
 
 // Next32 will return the next 32-bit id
 func (p *PIDG) Next32() (id ID32, err error) {
+	return p.Next32Context(context.Background())
+}
+
+// Next32Context will return the next 32-bit id, spans are parented to the provided context
+func (p *PIDG) Next32Context(ctx context.Context) (id ID32, err error) {
 	var idx uint64
-	p.mux.Update(func() {
-		idx = p.idx
-		// Perist value to disk
-		if err = p.persist(); err != nil {
-			return
-		}
-		// Increment index value
-		p.idx++
-	})
-	// Break early if error exists
-	if err != nil {
+	if idx, err = p.reserve(ctx, 1); err != nil {
 		return
 	}
 	// Set id with the retrieved index (utilizing a current timestamp)
-	id = newID32(uint32(idx), -1)
+	id = p.newID32(idx)
 	return
 }
 
+// Reserve will reserve a contiguous block of n indexes and return the first index of the block
+// Note: The end of the block is persisted before Reserve returns, the block will never be reissued.
+// ErrIndexExhausted is returned when the block would wrap past the last index
+func (p *PIDG) Reserve(n uint64) (start uint64, err error) {
+	return p.ReserveContext(context.Background(), n)
+}
+
+// ReserveContext will reserve a contiguous block of n indexes, spans are parented to the provided context
+func (p *PIDG) ReserveContext(ctx context.Context, n uint64) (start uint64, err error) {
+	if n == 0 {
+		err = ErrInvalidBlockSize
+		return
+	}
+
+	return p.reserve(ctx, n)
+}
+
 // Close will close the internal file
 func (p *PIDG) Close() (err error) {
 	p.mux.Update(func() {
-		err = p.pf.Close()
+		err = p.f.Close()
+		if p.j == nil {
+			return
+		}
+
+		if jerr := p.j.close(); err == nil {
+			err = jerr
+		}
 	})
 
+	p.closed(err)
 	return
 }
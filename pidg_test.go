@@ -71,6 +71,26 @@ func TestPIDGIndexing(t *testing.T) {
 
 }
 
+func TestPIDGKeys(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+
+	// Keys which are not valid network keys remain usable as long as they stay within the directory
+	for _, key := range []string{"orders.v1", "orders v1", "..orders"} {
+		pidg, err := NewPersistent(key, "./test_data")
+		if err != nil {
+			t.Fatalf("error opening %q: %v", key, err)
+		}
+
+		pidg.Close()
+	}
+
+	for _, key := range []string{"..", "../orders", "a/b", `a\b`} {
+		if _, err := NewPersistent(key, "./test_data"); err != ErrInvalidGeneratorKey {
+			t.Fatalf("invalid error for %q, expected %v and received %v", key, ErrInvalidGeneratorKey, err)
+		}
+	}
+}
+
 func BenchmarkPIDG_Gen(b *testing.B) {
 	var (
 		pidg *PIDG
@@ -0,0 +1,262 @@
+package idg
+
+import (
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/PathDNA/atoms"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidPrefix is returned when a prefix is not valid
+	ErrInvalidPrefix = errors.Error("invalid prefix, prefixes must be 1 to 16 lowercase alphanumeric characters")
+	// ErrPrefixRegistered is returned when a prefix has already been registered
+	ErrPrefixRegistered = errors.Error("prefix has already been registered")
+	// ErrPrefixMismatch is returned when a prefixed ID does not have the expected prefix
+	ErrPrefixMismatch = errors.Error("prefix does not match")
+)
+
+const (
+	// Separator between a prefix and the encoded ID
+	prefixSep = '_'
+	// Maximum length of a prefix
+	maxPrefixLen = 16
+)
+
+var (
+	prefixMux atoms.Mux
+	// Registered prefixes
+	prefixes = map[Prefix]struct{}{}
+)
+
+// RegisterPrefix will register a prefix for an entity type
+// Note: Each prefix can only be registered once to ensure entity types are distinguishable
+func RegisterPrefix(prefix string) (p Prefix, err error) {
+	p = Prefix(prefix)
+	if !p.isValid() {
+		err = ErrInvalidPrefix
+		return
+	}
+
+	prefixMux.Update(func() {
+		if _, ok := prefixes[p]; ok {
+			err = ErrPrefixRegistered
+			return
+		}
+
+		prefixes[p] = struct{}{}
+	})
+
+	return
+}
+
+// MustRegisterPrefix will register a prefix for an entity type and panic on error
+// Note: This is intended for package level variable declarations
+func MustRegisterPrefix(prefix string) (p Prefix) {
+	var err error
+	if p, err = RegisterPrefix(prefix); err != nil {
+		panic("idg: cannot register prefix \"" + prefix + "\": " + err.Error())
+	}
+
+	return
+}
+
+// Prefix represents an entity type prefix (e.g. "usr" for "usr_<id>")
+type Prefix string
+
+// isValid will return whether or not a prefix is valid
+func (p Prefix) isValid() bool {
+	if len(p) == 0 || len(p) > maxPrefixLen {
+		return false
+	}
+
+	for _, c := range []byte(p) {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Format will return the prefixed string representation of an ID
+func (p Prefix) Format(id ID) (out string) {
+	return string(p) + string(prefixSep) + id.String()
+}
+
+// Parse will parse a prefixed string id
+func (p Prefix) Parse(in string) (id ID, err error) {
+	var str string
+	if str, err = p.trim(in); err != nil {
+		return
+	}
+
+	return Parse(str)
+}
+
+// trim will remove the prefix and separator from the provided string
+func (p Prefix) trim(in string) (out string, err error) {
+	// The prefix cannot contain the separator, so the first separator ends the prefix
+	idx := strings.IndexByte(in, prefixSep)
+	if idx == -1 {
+		err = ErrInvalidPrefix
+		return
+	}
+
+	if Prefix(in[:idx]) != p {
+		err = ErrPrefixMismatch
+		return
+	}
+
+	out = in[idx+1:]
+	return
+}
+
+// Entity is implemented by the marker types utilized with TypedID
+// (e.g. type User struct{} with func (User) IDPrefix() idg.Prefix)
+type Entity interface {
+	IDPrefix() Prefix
+}
+
+// EncodedEntity is implemented by entity types which utilize their own text encoding
+type EncodedEntity interface {
+	Entity
+	IDEncoding() Encoding
+}
+
+// Typed will return a TypedID for the provided ID
+func Typed[T Entity](id ID) TypedID[T] {
+	return TypedID[T](id)
+}
+
+// ParseTyped will parse a prefixed string id, the prefix must match the prefix of T
+func ParseTyped[T Entity](in string) (id TypedID[T], err error) {
+	err = id.parse(in)
+	return
+}
+
+// TypedID is an ID which belongs to an entity type. TypedIDs of different entity
+// types cannot be mixed at compile time and are represented with their prefix
+type TypedID[T Entity] ID
+
+// prefix will return the prefix of the entity type
+func (id *TypedID[T]) prefix() Prefix {
+	var e T
+	return e.IDPrefix()
+}
+
+// encoding will return the text encoding of the entity type
+func (id *TypedID[T]) encoding() Encoding {
+	var e T
+	if ee, ok := any(e).(EncodedEntity); ok {
+		return ee.IDEncoding()
+	}
+
+	return idEnc
+}
+
+func (id *TypedID[T]) parse(in string) (err error) {
+	var str string
+	if str, err = id.prefix().trim(in); err != nil {
+		return
+	}
+
+	return decode(id.encoding(), id[:], []byte(str))
+}
+
+// Untyped will return the underlying ID
+func (id *TypedID[T]) Untyped() (out ID) {
+	if id == nil {
+		return
+	}
+
+	return ID(*id)
+}
+
+// Index will return the index of a TypedID
+func (id *TypedID[T]) Index() (idx uint64, err error) {
+	if id == nil {
+		err = ErrEmptyID
+		return
+	}
+
+	uid := ID(*id)
+	return uid.Index()
+}
+
+// Time will return the time.Time of a TypedID
+func (id *TypedID[T]) Time() (t time.Time, err error) {
+	if id == nil {
+		err = ErrEmptyID
+		return
+	}
+
+	uid := ID(*id)
+	return uid.Time()
+}
+
+// String will return the prefixed string representation
+func (id *TypedID[T]) String() (out string) {
+	if id == nil {
+		return
+	}
+
+	return string(id.prefix()) + string(prefixSep) + encode(id.encoding(), id[:])
+}
+
+// IsEmpty will return if a TypedID is empty
+func (id *TypedID[T]) IsEmpty() (empty bool) {
+	return id == nil || ID(*id) == emptyID
+}
+
+// MarshalJSON is a JSON encoding helper func
+// Note: Empty IDs are encoded as null
+func (id *TypedID[T]) MarshalJSON() (out []byte, err error) {
+	if id.IsEmpty() {
+		return append(out, jsonNull...), nil
+	}
+
+	return json.Marshal(id.String())
+}
+
+// UnmarshalJSON is a JSON decoding helper func
+// Note: null is decoded as an empty ID. Errors are of type *JSONError
+func (id *TypedID[T]) UnmarshalJSON(in []byte) (err error) {
+	if isJSONNull(in) {
+		*id = TypedID[T](emptyID)
+		return
+	}
+
+	var str []byte
+	// Unmarshal inbound value as a string
+	if str, err = jsonString(in); err != nil {
+		return newJSONError("TypedID", in, err)
+	}
+
+	var nid TypedID[T]
+	// The ID is only assigned once decoding has succeeded
+	if err = nid.parse(string(str)); err != nil {
+		return newJSONError("TypedID", in, err)
+	}
+
+	*id = nid
+	return
+}
+
+// NewTyped will return a new ID generator for an entity type
+func NewTyped[T Entity](idx uint64, ops ...Option) (t TypedIDG[T]) {
+	t.idg = New(idx, ops...)
+	return
+}
+
+// TypedIDG is a non-persistent atomic ID generator for an entity type
+type TypedIDG[T Entity] struct {
+	idg IDG
+}
+
+// Next will return the next id
+func (t *TypedIDG[T]) Next() (id TypedID[T]) {
+	return TypedID[T](t.idg.Next())
+}
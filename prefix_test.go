@@ -0,0 +1,81 @@
+package idg
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+var (
+	testUserPrefix  = MustRegisterPrefix("usr")
+	testOrderPrefix = MustRegisterPrefix("ord")
+)
+
+type testUser struct{}
+
+func (testUser) IDPrefix() Prefix { return testUserPrefix }
+
+type testOrder struct{}
+
+func (testOrder) IDPrefix() Prefix { return testOrderPrefix }
+
+func TestPrefix(t *testing.T) {
+	var (
+		nid ID
+		err error
+	)
+
+	id := newID(1337, -1)
+	str := testUserPrefix.Format(id)
+	if !strings.HasPrefix(str, "usr_") {
+		t.Fatalf("invalid prefix, expected %s and received %s", "usr_", str)
+	}
+
+	if nid, err = testUserPrefix.Parse(str); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	if _, err = testOrderPrefix.Parse(str); err != ErrPrefixMismatch {
+		t.Fatalf("invalid error, expected %v and received %v", ErrPrefixMismatch, err)
+	}
+
+	if _, err = RegisterPrefix("usr"); err != ErrPrefixRegistered {
+		t.Fatalf("invalid error, expected %v and received %v", ErrPrefixRegistered, err)
+	}
+
+	if _, err = RegisterPrefix("Bad_"); err != ErrInvalidPrefix {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidPrefix, err)
+	}
+}
+
+func TestTypedID(t *testing.T) {
+	var (
+		b   []byte
+		err error
+	)
+
+	gen := NewTyped[testUser](1337)
+	id := gen.Next()
+	if err = testIndex(id.Untyped(), 1337); err != nil {
+		t.Fatal(err)
+	}
+
+	if b, err = json.Marshal(&id); err != nil {
+		t.Fatal(err)
+	}
+
+	var nid TypedID[testUser]
+	if err = json.Unmarshal(b, &nid); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %s / %s", id.String(), nid.String())
+	}
+
+	// Order IDs should not parse as user IDs
+	oid := Typed[testOrder](id.Untyped())
+	if _, err = ParseTyped[testUser](oid.String()); err != ErrPrefixMismatch {
+		t.Fatalf("invalid error, expected %v and received %v", ErrPrefixMismatch, err)
+	}
+}
@@ -0,0 +1,116 @@
+package idg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidKey is returned when a key is too short to be utilized
+	ErrInvalidKey = errors.Error("invalid key, keys must be at least 16 bytes")
+)
+
+const (
+	// Minimum length of a public key secret
+	minKeyLen = 16
+	// Number of Feistel rounds
+	feistelRounds = 8
+)
+
+// NewPublicKey will return a new public key for the provided secret
+func NewPublicKey(secret []byte) (key *PublicKey, err error) {
+	if len(secret) < minKeyLen {
+		err = ErrInvalidKey
+		return
+	}
+
+	var k PublicKey
+	k.secret = append([]byte(nil), secret...)
+	key = &k
+	return
+}
+
+// PublicKey is utilized to obfuscate IDs for public consumption. IDs are encrypted
+// with a keyed Feistel network, which is a reversible permutation of the ID bytes.
+// The output is the same length as the input, so a public ID is still 16 (or 8) bytes
+// Note: This hides the index from customers, it is not intended as authentication.
+// Please see Signer for tamper detection
+type PublicKey struct {
+	secret []byte
+}
+
+// round will XOR the round function output for the provided half into dst
+func (k *PublicKey) round(r int, dst, half []byte) {
+	mac := hmac.New(sha256.New, k.secret)
+	mac.Write([]byte{byte(r), byte(len(half))})
+	mac.Write(half)
+	sum := mac.Sum(nil)
+	for i := range dst {
+		dst[i] ^= sum[i]
+	}
+}
+
+// encrypt will encrypt the provided bytes in place
+func (k *PublicKey) encrypt(b []byte) {
+	l, r := b[:len(b)/2], b[len(b)/2:]
+	for i := 0; i < feistelRounds; i++ {
+		// L ^= F(R), then swap halves
+		k.round(i, l, r)
+		l, r = r, l
+	}
+	// An even number of rounds leaves the halves in their original positions
+}
+
+// decrypt will decrypt the provided bytes in place
+func (k *PublicKey) decrypt(b []byte) {
+	l, r := b[:len(b)/2], b[len(b)/2:]
+	for i := feistelRounds - 1; i >= 0; i-- {
+		// Undo the swap, then R ^= F(L)
+		l, r = r, l
+		k.round(i, l, r)
+	}
+}
+
+// PublicString will return an obfuscated string representation
+func (id *ID) PublicString(key *PublicKey) (out string) {
+	if id == nil {
+		return
+	}
+
+	pub := *id
+	key.encrypt(pub[:])
+	return pub.String()
+}
+
+// PublicString will return an obfuscated string representation
+func (id *ID32) PublicString(key *PublicKey) (out string) {
+	if id == nil {
+		return
+	}
+
+	pub := *id
+	key.encrypt(pub[:])
+	return pub.String()
+}
+
+// ParsePublic will parse an obfuscated string id
+func ParsePublic(key *PublicKey, in string) (id ID, err error) {
+	if id, err = Parse(in); err != nil {
+		return
+	}
+
+	key.decrypt(id[:])
+	return
+}
+
+// ParsePublic32 will parse an obfuscated 32-bit string id
+func ParsePublic32(key *PublicKey, in string) (id ID32, err error) {
+	if id, err = Parse32(in); err != nil {
+		return
+	}
+
+	key.decrypt(id[:])
+	return
+}
@@ -0,0 +1,65 @@
+package idg
+
+import "testing"
+
+func TestPublic(t *testing.T) {
+	var (
+		key *PublicKey
+		nid ID
+		err error
+	)
+
+	if key, err = NewPublicKey([]byte("0123456789abcdef")); err != nil {
+		t.Fatal(err)
+	}
+
+	id := newID(1337, -1)
+	pub := id.PublicString(key)
+	if pub == id.String() {
+		t.Fatalf("public string matches plain string: %s", pub)
+	}
+
+	if nid, err = ParsePublic(key, pub); err != nil {
+		t.Fatal(err)
+	}
+
+	if id != nid {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	// Sequential IDs should not produce similar public strings
+	next := newID(1338, -1)
+	a, b := id.PublicString(key), next.PublicString(key)
+	if a[:8] == b[:8] {
+		t.Fatalf("sequential public strings share a prefix: %s / %s", a, b)
+	}
+
+	// A different key should not be able to recover the ID
+	other, _ := NewPublicKey([]byte("fedcba9876543210"))
+	if nid, err = ParsePublic(other, pub); err != nil {
+		t.Fatal(err)
+	} else if nid == id {
+		t.Fatal("ID was recovered with the wrong key")
+	}
+
+	if _, err = NewPublicKey([]byte("short")); err != ErrInvalidKey {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidKey, err)
+	}
+}
+
+func TestPublic32(t *testing.T) {
+	var (
+		nid ID32
+		err error
+	)
+
+	key, _ := NewPublicKey([]byte("0123456789abcdef"))
+	id := newID32(1337, -1)
+	if nid, err = ParsePublic32(key, id.PublicString(key)); err != nil {
+		t.Fatal(err)
+	}
+
+	if id != nid {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+}
@@ -0,0 +1,650 @@
+package idg
+
+import (
+	"container/list"
+	"context"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PathDNA/atoms"
+	"github.com/itsmontoya/mum"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrRegistryClosed is returned when a closed registry is utilized
+	ErrRegistryClosed = errors.Error("registry is closed")
+	// ErrInvalidRegistryKey is returned when a registry key is not a valid key, see IsValidKey
+	ErrInvalidRegistryKey = errors.Error("invalid registry key")
+	// ErrJournalUnsupported is returned when WithJournal is provided to a registry file
+	ErrJournalUnsupported = errors.Error("journaling is not supported by registry files")
+	// ErrCheckpointsUnsupported is returned when WithCheckpoints is provided to a registry, the
+	// checkpoint log would be shared by every key
+	ErrCheckpointsUnsupported = errors.Error("checkpoints are not supported by registries")
+)
+
+// DefaultMaxOpen is the default maximum number of open generators of a directory registry
+const DefaultMaxOpen = 256
+
+const (
+	// Magic bytes of the registry file format, followed by the version byte and padding
+	registryMagic   = "IDGR"
+	registryVersion = 1
+	// Length of the registry file header
+	registryHeaderLen = 8
+	// Length of a copy of a slot, key length (1), key (64), index (8), checksum (4) and padding (3)
+	registryCopyLen = 80
+	// Length of a registry slot, each slot holds two copies which are written alternately so a
+	// torn write only damages the older copy
+	registrySlotLen = 2 * registryCopyLen
+	// Offset of the index (and the checksummed length) within a copy
+	registrySlotIdx = 65
+	registrySlotCRC = 73
+)
+
+// WithMaxOpen will set the maximum number of open generators of a directory registry, the
+// least recently used idle generator is closed (and reopened on demand) past the limit
+// Note: DefaultMaxOpen is utilized when unset, a negative n disables the limit. This is not
+// utilized by generators or registry files
+func WithMaxOpen(n int) Option {
+	return func(o *opts) {
+		o.maxOpen = n
+	}
+}
+
+// NewRegistry will return a new registry of persistent generators within a directory
+// Note: Generators are opened (utilizing NewPersistent) on first use and each open generator
+// holds a file handle. At most DefaultMaxOpen generators (see WithMaxOpen) are open at once,
+// generators in use are not closed so the limit may be exceeded briefly. Utilize
+// OpenRegistryFile for a single handle shared by every key.
+// ErrCheckpointsUnsupported is returned when WithCheckpoints is provided
+func NewRegistry(dir string, ops ...Option) (r *Registry, err error) {
+	var o opts
+	if o.apply(ops); o.checkpoints != nil {
+		err = ErrCheckpointsUnsupported
+		return
+	}
+
+	if err = os.MkdirAll(dir, 0744); err != nil {
+		return
+	}
+
+	var reg Registry
+	reg.ops = ops
+	reg.dir = dir
+	reg.gens = make(map[string]*dirGen)
+	reg.lru = list.New()
+	if reg.maxOpen = DefaultMaxOpen; o.maxOpen != 0 {
+		reg.maxOpen = o.maxOpen
+	}
+
+	r = &reg
+	return
+}
+
+// OpenRegistryFile will open (or create) a registry which stores every key within a single file
+// Note: The file is locked while open and a single file handle is utilized for all keys. A key
+// whose slot is corrupt (both copies fail their checksum) returns ErrFileChecksum while the
+// remaining keys are unaffected. ErrJournalUnsupported is returned when WithJournal is provided
+// and ErrCheckpointsUnsupported is returned when WithCheckpoints is provided
+func OpenRegistryFile(fp string, ops ...Option) (r *Registry, err error) {
+	var o opts
+	switch o.apply(ops); {
+	case o.journal != nil:
+		err = ErrJournalUnsupported
+		return
+	case o.checkpoints != nil:
+		err = ErrCheckpointsUnsupported
+		return
+	}
+
+	var reg Registry
+	reg.ops = ops
+	reg.counters = make(map[string]*counter)
+	reg.corrupt = make(map[string]struct{})
+	if err = os.MkdirAll(filepath.Dir(fp), 0744); err != nil {
+		return
+	}
+
+	if reg.f, err = os.OpenFile(fp, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+		return
+	}
+
+	if err = lockFile(reg.f); err != nil {
+		reg.f.Close()
+		return
+	}
+
+	if err = reg.read(); err != nil {
+		reg.f.Close()
+		return
+	}
+
+	r = &reg
+	return
+}
+
+// Registry manages many named persistent counters
+type Registry struct {
+	mux atoms.Mux
+	// Options applied to each key
+	ops []Option
+
+	// Directory of the generators, empty for registry files
+	dir string
+	// Opened generators by key
+	gens map[string]*dirGen
+	// Opened generators, most recently used first
+	lru *list.List
+	// Maximum number of opened generators, negative for no limit
+	maxOpen int
+
+	// Registry file, nil for directory registries
+	f *os.File
+	// Counters of the registry file by key
+	counters map[string]*counter
+	// Keys of the registry file with corrupt slots
+	corrupt map[string]struct{}
+	// Set when a corrupt slot does not hold a readable key, new keys cannot be allocated
+	// as the key may be the key of the corrupt slot
+	unknownCorrupt bool
+	// Offset of the next slot to be allocated
+	end int64
+
+	closed bool
+}
+
+// read will read and verify the header and slots of the registry file
+func (r *Registry) read() (err error) {
+	var b []byte
+	if b, err = io.ReadAll(r.f); err != nil {
+		return
+	}
+
+	if len(b) == 0 {
+		// New file, write the header
+		var hdr [registryHeaderLen]byte
+		copy(hdr[:], registryMagic)
+		hdr[4] = registryVersion
+		if _, err = r.f.WriteAt(hdr[:], 0); err != nil {
+			return
+		}
+
+		r.end = registryHeaderLen
+		return r.f.Sync()
+	}
+
+	if len(b) < registryHeaderLen || string(b[:4]) != registryMagic || b[4] != registryVersion {
+		return ErrInvalidFile
+	}
+
+	// A trailing partial slot is a torn allocation which was never utilized
+	n := (len(b) - registryHeaderLen) / registrySlotLen
+	r.end = registryHeaderLen
+	for i := 0; i < n; i++ {
+		off := registryHeaderLen + i*registrySlotLen
+		r.readSlot(b[off:off+registrySlotLen], int64(off), i == n-1)
+	}
+
+	if r.end == int64(len(b)) {
+		return
+	}
+
+	return r.f.Truncate(r.end)
+}
+
+// readSlot will read the slot at the provided offset, corrupt slots are quarantined
+func (r *Registry) readSlot(slot []byte, off int64, last bool) {
+	var (
+		c     *counter
+		valid [2]bool
+		idxs  [2]uint64
+		keys  [2]string
+	)
+
+	for i := range valid {
+		keys[i], idxs[i], valid[i] = readSlotCopy(slot[i*registryCopyLen : (i+1)*registryCopyLen])
+	}
+
+	switch {
+	case valid[0] && valid[1] && keys[0] == keys[1]:
+		// The latest copy holds the highest index
+		c = r.newCounter(keys[0], off)
+		if c.idx, c.cur = idxs[0], 0; idxs[1] >= idxs[0] {
+			c.idx, c.cur = idxs[1], 1
+		}
+	case valid[0] && !valid[1]:
+		c = r.newCounter(keys[0], off)
+		c.idx, c.cur = idxs[0], 0
+	case valid[1] && !valid[0]:
+		c = r.newCounter(keys[1], off)
+		c.idx, c.cur = idxs[1], 1
+	case last && !valid[0] && isZero(slot[registryCopyLen:]):
+		// A torn allocation, the slot was never utilized and is truncated
+		return
+	default:
+		// Both copies are corrupt, quarantine the key without failing the remaining keys
+		r.quarantine(slot)
+		r.end = off + registrySlotLen
+		return
+	}
+
+	r.counters[c.key] = c
+	r.end = off + registrySlotLen
+}
+
+// quarantine will mark the keys held by the copies of a corrupt slot as corrupt
+func (r *Registry) quarantine(slot []byte) {
+	var found bool
+	for i := 0; i < 2; i++ {
+		cp := slot[i*registryCopyLen : (i+1)*registryCopyLen]
+		if klen := int(cp[0]); klen <= 64 && IsValidKey(string(cp[1:1+klen])) {
+			r.corrupt[string(cp[1:1+klen])] = struct{}{}
+			found = true
+		}
+	}
+
+	if !found {
+		r.unknownCorrupt = true
+	}
+}
+
+// readSlotCopy will read and verify a copy of a slot
+func readSlotCopy(cp []byte) (key string, idx uint64, ok bool) {
+	if crc32.Checksum(cp[:registrySlotCRC], crcTable) != binary.BigEndian.Uint32(cp[registrySlotCRC:]) {
+		return
+	}
+
+	klen := int(cp[0])
+	if klen > 64 {
+		return
+	}
+
+	if key = string(cp[1 : 1+klen]); !IsValidKey(key) {
+		return
+	}
+
+	var br mum.BinaryReader
+	var err error
+	if idx, err = br.Uint64(cp[registrySlotIdx:registrySlotCRC]); err != nil {
+		return
+	}
+
+	ok = true
+	return
+}
+
+// newCounter will return a new counter of a registry file with the provided slot offset
+func (r *Registry) newCounter(key string, off int64) (c *counter) {
+	c = &counter{f: r.f, off: off}
+	c.opts.apply(r.ops)
+	c.key = key
+	return
+}
+
+// dirGen is an opened generator of a directory registry
+type dirGen struct {
+	p *PIDG
+	// Number of reservations in progress, generators are only closed when idle
+	refs int
+	// Element of the generator within the least recently used list
+	e *list.Element
+}
+
+// acquire will return the generator of a directory registry key, opening it as needed
+// Note: The generator is not closed until it is released
+func (r *Registry) acquire(key string) (g *dirGen, err error) {
+	r.mux.Update(func() {
+		if r.closed {
+			err = ErrRegistryClosed
+			return
+		}
+
+		if g = r.gens[key]; g != nil {
+			r.lru.MoveToFront(g.e)
+			g.refs++
+			return
+		}
+
+		r.evict()
+		var p *PIDG
+		if p, err = NewPersistent(key, r.dir, r.ops...); err != nil {
+			return
+		}
+
+		g = &dirGen{p: p, refs: 1}
+		g.e = r.lru.PushFront(g)
+		r.gens[key] = g
+	})
+
+	return
+}
+
+// release will release a generator returned by acquire
+func (r *Registry) release(g *dirGen) {
+	r.mux.Update(func() {
+		g.refs--
+	})
+}
+
+// evict will close the least recently used idle generators until a generator can be opened
+// within the limit
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (r *Registry) evict() {
+	if r.maxOpen < 0 {
+		return
+	}
+
+	for e := r.lru.Back(); e != nil && len(r.gens) >= r.maxOpen; {
+		g := e.Value.(*dirGen)
+		e = e.Prev()
+		if g.refs > 0 {
+			continue
+		}
+
+		// Close errors are reported by the generator, the index was persisted by each reservation
+		g.p.Close()
+		r.lru.Remove(g.e)
+		delete(r.gens, g.p.key)
+	}
+}
+
+// counter will return the counter of a registry file key, allocating a slot as needed
+func (r *Registry) counter(key string) (c *counter, err error) {
+	var closed bool
+	var corrupt bool
+	r.mux.Read(func() {
+		c = r.counters[key]
+		_, corrupt = r.corrupt[key]
+		closed = r.closed
+	})
+
+	switch {
+	case closed:
+		return nil, ErrRegistryClosed
+	case corrupt:
+		return nil, ErrFileChecksum
+	case c != nil:
+		return
+	}
+
+	r.mux.Update(func() {
+		if r.closed {
+			err = ErrRegistryClosed
+			return
+		}
+
+		// Check again, the key may have been allocated while acquiring the lock
+		if c = r.counters[key]; c != nil {
+			return
+		}
+
+		if r.unknownCorrupt {
+			// The key may be the key of a corrupt slot, allocating a new slot could reissue indexes
+			err = ErrFileChecksum
+			return
+		}
+
+		nc := r.newCounter(key, r.end)
+		// Allocate the slot of the key prior to issuing any index
+		if err = nc.alloc(); err != nil {
+			nc.persistFailed(0, err)
+			return
+		}
+
+		nc.opened(0)
+		r.end += registrySlotLen
+		r.counters[key] = nc
+		c = nc
+	})
+
+	return
+}
+
+// reserve will reserve a block of n indexes of the provided key, o holds the options of the key
+func (r *Registry) reserve(ctx context.Context, key string, n uint64) (start uint64, o *opts, err error) {
+	if !IsValidKey(key) {
+		err = ErrInvalidRegistryKey
+		return
+	}
+
+	if r.f == nil {
+		var g *dirGen
+		if g, err = r.acquire(key); err != nil {
+			return
+		}
+		defer r.release(g)
+
+		start, err = g.p.reserve(ctx, n)
+		return start, &g.p.opts, err
+	}
+
+	var c *counter
+	if c, err = r.counter(key); err != nil {
+		return
+	}
+
+	start, err = c.reserve(ctx, n)
+	return start, &c.opts, err
+}
+
+// Next will return the next id of the provided key
+func (r *Registry) Next(key string) (id ID, err error) {
+	var (
+		idx uint64
+		o   *opts
+	)
+
+	if idx, o, err = r.reserve(context.Background(), key, 1); err != nil {
+		return
+	}
+
+	id = o.newID(idx)
+	return
+}
+
+// Next32 will return the next 32-bit id of the provided key
+func (r *Registry) Next32(key string) (id ID32, err error) {
+	var (
+		idx uint64
+		o   *opts
+	)
+
+	if idx, o, err = r.reserve(context.Background(), key, 1); err != nil {
+		return
+	}
+
+	id = o.newID32(idx)
+	return
+}
+
+// Reserve will reserve a contiguous block of n indexes of the provided key and return the
+// first index of the block
+// Note: ErrIndexExhausted is returned when the block would wrap past the last index
+func (r *Registry) Reserve(key string, n uint64) (start uint64, err error) {
+	if n == 0 {
+		err = ErrInvalidBlockSize
+		return
+	}
+
+	start, _, err = r.reserve(context.Background(), key, n)
+	return
+}
+
+// Keys will return the sorted keys of the registry, including keys which have not been opened
+func (r *Registry) Keys() (keys []string, err error) {
+	if r.f != nil {
+		r.mux.Read(func() {
+			for key := range r.counters {
+				keys = append(keys, key)
+			}
+
+			for key := range r.corrupt {
+				keys = append(keys, key)
+			}
+		})
+
+		sort.Strings(keys)
+		return
+	}
+
+	var fps []string
+	if fps, err = ListFiles(r.dir); err != nil {
+		return
+	}
+
+	for _, fp := range fps {
+		keys = append(keys, strings.TrimSuffix(filepath.Base(fp), FileExt))
+	}
+
+	return
+}
+
+// Close will close every generator of the registry
+// Note: The first error encountered is returned, all generators are closed regardless
+func (r *Registry) Close() (err error) {
+	r.mux.Update(func() {
+		if r.closed {
+			err = ErrRegistryClosed
+			return
+		}
+
+		r.closed = true
+		for _, g := range r.gens {
+			if perr := g.p.Close(); err == nil {
+				err = perr
+			}
+		}
+
+		if r.f == nil {
+			return
+		}
+
+		ferr := r.f.Close()
+		if err == nil {
+			err = ferr
+		}
+
+		for _, c := range r.counters {
+			c.closed(ferr)
+		}
+	})
+
+	return
+}
+
+// counter is a persistent counter stored within a slot of a registry file
+type counter struct {
+	opts
+
+	mux atoms.Mux
+	// Registry file
+	f *os.File
+	// Offset of the slot within the registry file
+	off int64
+	// Next index to be issued
+	idx uint64
+	// Copy of the slot which was last written
+	cur int
+	// Write buffer
+	buf [registrySlotLen]byte
+}
+
+// encode will encode the provided next index to the provided copy of the write buffer
+func (c *counter) encode(cp int, idx uint64) (b []byte) {
+	var bw mum.BinaryWriter
+	b = c.buf[cp*registryCopyLen : (cp+1)*registryCopyLen]
+	b[0] = byte(len(c.key))
+	copy(b[1:registrySlotIdx], c.key)
+	copy(b[registrySlotIdx:registrySlotCRC], bw.Uint64(idx))
+	binary.BigEndian.PutUint32(b[registrySlotCRC:], crc32.Checksum(b[:registrySlotCRC], crcTable))
+	return
+}
+
+// alloc will allocate the slot of the counter, the first copy holds an index of 0 and the
+// second copy is zero-filled
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (c *counter) alloc() (err error) {
+	c.encode(0, 0)
+	if _, err = c.f.WriteAt(c.buf[:], c.off); err != nil {
+		return
+	}
+
+	if err = c.f.Sync(); err != nil {
+		c.syncFailed(err)
+	}
+
+	return
+}
+
+// write will persist the provided next index to the older copy of the slot of the counter
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (c *counter) write(idx uint64, sync bool) (err error) {
+	cp := 1 - c.cur
+	// The copy is written with a single write so a torn write is detected by the checksum,
+	// the latest copy is left intact until the write has completed
+	if _, err = c.f.WriteAt(c.encode(cp, idx), c.off+int64(cp*registryCopyLen)); err != nil {
+		return
+	}
+
+	if c.cur = cp; !sync {
+		return
+	}
+
+	if err = c.f.Sync(); err != nil {
+		c.syncFailed(err)
+	}
+
+	return
+}
+
+// persist will store the next index to the registry file following a reservation of n indexes
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (c *counter) persist(ctx context.Context, next, n uint64) (err error) {
+	_, span := c.startSpan(ctx, spanPersist, backendFile, n)
+	defer func() { endSpan(span, err) }()
+
+	var start time.Time
+	if c.metrics != nil {
+		start = time.Now()
+	}
+
+	err = c.write(next, c.sync)
+	c.persisted(start, err)
+	if err != nil {
+		c.persistFailed(next, err)
+	}
+
+	return
+}
+
+// reserve will reserve and persist a block of n indexes
+func (c *counter) reserve(ctx context.Context, n uint64) (start uint64, err error) {
+	ctx, span := c.startSpan(ctx, spanReserve, backendFile, n)
+	c.mux.Update(func() {
+		if start = c.blockStart(c.idx, n); start+n < start {
+			c.overflowed(start)
+			err = ErrIndexExhausted
+			return
+		}
+		// Perist the index following the end of the block to the registry file
+		if err = c.persist(ctx, start+n, n); err != nil {
+			return
+		}
+		// Increment index value past the end of the block
+		c.idx = start + n
+	})
+
+	endSpan(span, err)
+	return
+}
@@ -0,0 +1,288 @@
+package idg
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	r, err := NewRegistry("./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testRegistry(t, r)
+	// Keys are persisted as generator files
+	if _, err = os.Stat(filepath.Join("./test_data", "orders"+FileExt)); err != nil {
+		t.Fatal(err)
+	}
+
+	if r, err = NewRegistry("./test_data"); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	testRegistryReopened(t, r)
+}
+
+func TestRegistryMaxOpen(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	r, err := NewRegistry("./test_data", WithMaxOpen(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	keys := []string{"a", "b", "c", "d", "e"}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if _, err := r.Next(keys[j%len(keys)]); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	r.mux.Read(func() {
+		if len(r.gens) > 2 || r.lru.Len() != len(r.gens) {
+			t.Errorf("invalid number of open generators: %d / %d", len(r.gens), r.lru.Len())
+		}
+	})
+
+	// Closed generators are reopened on demand without reissuing indexes
+	for _, key := range keys {
+		var start uint64
+		if start, err = r.Reserve(key, 1); err != nil || start != 40 {
+			t.Fatalf("invalid index of %s, expected %d and received %d (%v)", key, 40, start, err)
+		}
+	}
+
+	// The least recently used generators are closed and their files unlocked
+	var f *File
+	if f, err = OpenFile(filepath.Join("./test_data", "a"+FileExt)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = f.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistryFile(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	fp := filepath.Join("./test_data", "counters.idgr")
+	r, err := OpenRegistryFile(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = OpenRegistryFile(fp); err != ErrLocked {
+		t.Fatalf("invalid error, expected %v and received %v", ErrLocked, err)
+	}
+
+	testRegistry(t, r)
+	if r, err = OpenRegistryFile(fp); err != nil {
+		t.Fatal(err)
+	}
+
+	testRegistryReopened(t, r)
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Keys are sorted by slot, orders is the first slot and users is the second slot
+	corrupt := func(off int64) {
+		f, err := os.OpenFile(fp, os.O_RDWR, 0644)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer f.Close()
+
+		if _, err = f.WriteAt([]byte{0xff}, off); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A corrupt copy of a slot is ignored in favour of the other copy
+	corrupt(registryHeaderLen + registrySlotIdx)
+	if r, err = OpenRegistryFile(fp); err != nil {
+		t.Fatal(err)
+	}
+
+	var id ID
+	if id, err = r.Next("orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 101); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both copies of the first slot are corrupt, only the key of the slot is quarantined
+	corrupt(registryHeaderLen + registrySlotIdx)
+	corrupt(registryHeaderLen + registryCopyLen + registrySlotIdx)
+	if r, err = OpenRegistryFile(fp); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	if _, err = r.Next("orders"); err != ErrFileChecksum {
+		t.Fatalf("invalid error, expected %v and received %v", ErrFileChecksum, err)
+	}
+
+	if id, err = r.Next("users"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 11); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.Next("sessions"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRegistryFileTornAllocation(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	fp := filepath.Join("./test_data", "counters.idgr")
+	r, err := OpenRegistryFile(fp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.Next("orders"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate a torn allocation of a second slot
+	var f *os.File
+	if f, err = os.OpenFile(fp, os.O_APPEND|os.O_WRONLY, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	f.Write(make([]byte, registrySlotLen))
+	f.Close()
+
+	if r, err = OpenRegistryFile(fp); err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	var keys []string
+	if keys, err = r.Keys(); err != nil || len(keys) != 1 {
+		t.Fatalf("invalid keys: %v (%v)", keys, err)
+	}
+
+	var fi os.FileInfo
+	if fi, err = os.Stat(fp); err != nil {
+		t.Fatal(err)
+	}
+
+	if fi.Size() != registryHeaderLen+registrySlotLen {
+		t.Fatalf("invalid size, expected %d and received %d", registryHeaderLen+registrySlotLen, fi.Size())
+	}
+}
+
+func TestRegistryFileJournal(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	fp := filepath.Join("./test_data", "counters.idgr")
+	if _, err := OpenRegistryFile(fp, WithJournal(JournalConfig{})); err != ErrJournalUnsupported {
+		t.Fatalf("invalid error, expected %v and received %v", ErrJournalUnsupported, err)
+	}
+
+	// A checkpoint log would be shared by every key
+	c, err := NewCheckpoints(time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = OpenRegistryFile(fp, WithCheckpoints(c)); err != ErrCheckpointsUnsupported {
+		t.Fatalf("invalid error, expected %v and received %v", ErrCheckpointsUnsupported, err)
+	}
+
+	if _, err = NewRegistry("./test_data", WithCheckpoints(c)); err != ErrCheckpointsUnsupported {
+		t.Fatalf("invalid error, expected %v and received %v", ErrCheckpointsUnsupported, err)
+	}
+}
+
+func testRegistry(t *testing.T, r *Registry) {
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 25; j++ {
+				if _, err := r.Next("orders"); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+	id, err := r.Next("users")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.Next("../orders"); err != ErrInvalidRegistryKey {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidRegistryKey, err)
+	}
+
+	if err = r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = r.Next("orders"); err != ErrRegistryClosed {
+		t.Fatalf("invalid error, expected %v and received %v", ErrRegistryClosed, err)
+	}
+}
+
+func testRegistryReopened(t *testing.T, r *Registry) {
+	keys, err := r.Keys()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(keys) != 2 || keys[0] != "orders" || keys[1] != "users" {
+		t.Fatalf("invalid keys: %v", keys)
+	}
+
+	id, err := r.Next("orders")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = testIndex(id, 100); err != nil {
+		t.Fatal(err)
+	}
+
+	var start uint64
+	if start, err = r.Reserve("users", 10); err != nil || start != 1 {
+		t.Fatalf("invalid index, expected %d and received %d (%v)", 1, start, err)
+	}
+}
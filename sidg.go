@@ -0,0 +1,98 @@
+package idg
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/PathDNA/atoms"
+)
+
+// NewSnowflake will return a new Snowflake ID generator for the provided node
+func NewSnowflake(node uint64, sf Snowflake, ops ...Option) (sidg *SIDG, err error) {
+	if err = sf.validate(); err != nil {
+		return
+	}
+
+	if node > mask(sf.NodeBits) {
+		err = ErrInvalidNode
+		return
+	}
+
+	var s SIDG
+	s.opts.apply(ops)
+	s.sf = sf
+	s.node = node
+	s.last = -1
+	s.opened(0, slog.Uint64("node", node))
+	sidg = &s
+	return
+}
+
+// SIDG is a Snowflake-compatible 64-bit ID generator
+type SIDG struct {
+	opts
+
+	mux atoms.Mux
+	// Bit layout of generated IDs
+	sf Snowflake
+	// Node of the generator
+	node uint64
+	// Time (in units since epoch) of the last generated ID
+	last int64
+	// Sequence within the last time unit
+	seq uint64
+}
+
+// Layout will return the Snowflake layout of the generator
+func (s *SIDG) Layout() (sf Snowflake) {
+	return s.sf
+}
+
+// Next will return the next id
+// Note: When the sequence is exhausted within a single time unit, Next will
+// wait until the next time unit before returning
+func (s *SIDG) Next() (id ID64, err error) {
+	s.mux.Update(func() {
+		now := s.sf.ticks(time.Now())
+		switch {
+		case now < s.last:
+			// Clock has moved backwards, issuing IDs now could create duplicates
+			s.clockRegressed(time.Duration(s.last-now) * s.sf.Unit)
+			err = ErrClockRegression
+			return
+		case now == s.last:
+			if s.seq = (s.seq + 1) & mask(s.sf.SequenceBits); s.seq == 0 {
+				// Sequence has been exhausted, wait for the next time unit
+				now = s.waitNext()
+			}
+
+		default:
+			s.seq = 0
+		}
+
+		s.last = now
+		if id, err = s.sf.Compose(s.sf.Epoch.Add(time.Duration(now)*s.sf.Unit), s.node, s.seq); err != nil {
+			if err == ErrTimeOverflow {
+				s.overflowed(uint64(now))
+			}
+
+			return
+		}
+
+		s.issued(1)
+	})
+
+	return
+}
+
+// waitNext will sleep until the time unit following the last time unit
+// Note: This is NOT thread-safe, please ensure locking is
+// handled by the calling func
+func (s *SIDG) waitNext() (now int64) {
+	for now = s.sf.ticks(time.Now()); now <= s.last; now = s.sf.ticks(time.Now()) {
+		next := s.sf.Epoch.Add(time.Duration(s.last+1) * s.sf.Unit)
+		time.Sleep(time.Until(next))
+	}
+
+	return
+}
@@ -0,0 +1,205 @@
+package idg
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+
+	"github.com/PathDNA/atoms"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidSignature is returned when a signed ID has been forged or altered
+	ErrInvalidSignature = errors.Error("invalid signature")
+	// ErrUnknownKeyID is returned when a signed ID references a key which is not registered
+	ErrUnknownKeyID = errors.Error("unknown key ID")
+)
+
+// Strict encoding of signed IDs, non-zero trailing bits are rejected so each signed ID
+// has exactly one string representation
+var signedEnc = base64.RawURLEncoding.Strict()
+
+const (
+	// Length of the truncated HMAC appended to signed IDs
+	macLen = 10
+	// Length of the signature (key ID and truncated HMAC)
+	sigLen = 1 + macLen
+)
+
+// NewSigner will return a new Signer which signs with the provided key
+func NewSigner(keyID byte, key []byte) (s *Signer, err error) {
+	var signer Signer
+	signer.keys = make(map[byte][]byte)
+	if err = signer.AddKey(keyID, key); err != nil {
+		return
+	}
+
+	signer.active = keyID
+	s = &signer
+	return
+}
+
+// Signer signs and verifies IDs. A signed ID is the ID bytes followed by the ID of the
+// signing key and a truncated HMAC-SHA256. Multiple keys can be registered to allow
+// rotation, IDs signed with any registered key will be verified
+type Signer struct {
+	mux atoms.Mux
+	// Registered keys by key ID
+	keys map[byte][]byte
+	// Key ID utilized for signing
+	active byte
+}
+
+// AddKey will register a key which can be utilized for verification
+func (s *Signer) AddKey(keyID byte, key []byte) (err error) {
+	if len(key) < minKeyLen {
+		return ErrInvalidKey
+	}
+
+	s.mux.Update(func() {
+		s.keys[keyID] = append([]byte(nil), key...)
+	})
+
+	return
+}
+
+// RemoveKey will remove a registered key, IDs signed with the key will no longer verify
+// Note: The active key cannot be removed
+func (s *Signer) RemoveKey(keyID byte) {
+	s.mux.Update(func() {
+		if keyID != s.active {
+			delete(s.keys, keyID)
+		}
+	})
+}
+
+// SetActive will set the key utilized for signing
+func (s *Signer) SetActive(keyID byte) (err error) {
+	s.mux.Update(func() {
+		if _, ok := s.keys[keyID]; !ok {
+			err = ErrUnknownKeyID
+			return
+		}
+
+		s.active = keyID
+	})
+
+	return
+}
+
+// Sign will return the signed string representation of an ID
+func (s *Signer) Sign(id ID) (out string) {
+	return s.sign(id[:])
+}
+
+// Sign32 will return the signed string representation of an ID32
+func (s *Signer) Sign32(id ID32) (out string) {
+	return s.sign(id[:])
+}
+
+// Verify will return an error if the provided signed ID was not signed by a registered key
+func (s *Signer) Verify(in string) (err error) {
+	_, err = s.verify(in)
+	return
+}
+
+// ParseSigned will parse and verify a signed string id
+func (s *Signer) ParseSigned(in string) (id ID, err error) {
+	var payload []byte
+	if payload, err = s.verify(in); err != nil {
+		return
+	}
+
+	if len(payload) != len(id) {
+		err = ErrInvalidLength
+		return
+	}
+
+	copy(id[:], payload)
+	return
+}
+
+// ParseSigned32 will parse and verify a signed 32-bit string id
+func (s *Signer) ParseSigned32(in string) (id ID32, err error) {
+	var payload []byte
+	if payload, err = s.verify(in); err != nil {
+		return
+	}
+
+	if len(payload) != len(id) {
+		err = ErrInvalidLength
+		return
+	}
+
+	copy(id[:], payload)
+	return
+}
+
+// sign will return the signed string representation of the provided payload
+func (s *Signer) sign(payload []byte) (out string) {
+	var (
+		keyID byte
+		key   []byte
+	)
+
+	s.mux.Read(func() {
+		keyID = s.active
+		key = s.keys[keyID]
+	})
+
+	buf := make([]byte, 0, len(payload)+sigLen)
+	buf = append(buf, payload...)
+	buf = append(buf, keyID)
+	buf = append(buf, mac(key, keyID, payload)...)
+	return signedEnc.EncodeToString(buf)
+}
+
+// verify will return the payload of the provided signed string after verifying the signature
+// Note: Non-canonical strings (e.g. altered trailing bits or line breaks) are rejected with
+// ErrInvalidSignature, a signed ID cannot be altered and still verify
+func (s *Signer) verify(in string) (payload []byte, err error) {
+	var b []byte
+	if b, err = signedEnc.DecodeString(in); err != nil || signedEnc.EncodeToString(b) != in {
+		err = ErrInvalidSignature
+		return
+	}
+
+	if n := len(b) - sigLen; n != len(ID{}) && n != len(ID32{}) {
+		err = ErrInvalidLength
+		return
+	}
+
+	var (
+		key []byte
+		ok  bool
+	)
+
+	payload = b[:len(b)-sigLen]
+	keyID := b[len(payload)]
+	s.mux.Read(func() {
+		key, ok = s.keys[keyID]
+	})
+
+	if !ok {
+		payload = nil
+		err = ErrUnknownKeyID
+		return
+	}
+
+	if !hmac.Equal(b[len(payload)+1:], mac(key, keyID, payload)) {
+		payload = nil
+		err = ErrInvalidSignature
+		return
+	}
+
+	return
+}
+
+// mac will return the truncated HMAC of the provided key ID and payload
+func mac(key []byte, keyID byte, payload []byte) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte{keyID})
+	h.Write(payload)
+	return h.Sum(nil)[:macLen]
+}
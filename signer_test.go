@@ -0,0 +1,99 @@
+package idg
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSigner(t *testing.T) {
+	var (
+		s   *Signer
+		nid ID
+		err error
+	)
+
+	if s, err = NewSigner(1, []byte("0123456789abcdef")); err != nil {
+		t.Fatal(err)
+	}
+
+	id := newID(1337, -1)
+	signed := s.Sign(id)
+	if nid, err = s.ParseSigned(signed); err != nil {
+		t.Fatal(err)
+	}
+
+	if id != nid {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	// Alter the first character of the signed ID
+	altered := []byte(signed)
+	if altered[0] == 'A' {
+		altered[0] = 'B'
+	} else {
+		altered[0] = 'A'
+	}
+
+	if err = s.Verify(string(altered)); err != ErrInvalidSignature {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidSignature, err)
+	}
+
+	// Rotate to a new key, IDs signed with the old key should still verify
+	if err = s.AddKey(2, []byte("fedcba9876543210")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.SetActive(2); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Verify(signed); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = s.Verify(s.Sign(id)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Once the old key is removed, IDs signed with it should be rejected
+	s.RemoveKey(1)
+	if err = s.Verify(signed); err != ErrUnknownKeyID {
+		t.Fatalf("invalid error, expected %v and received %v", ErrUnknownKeyID, err)
+	}
+}
+
+func TestSigner32(t *testing.T) {
+	var (
+		nid ID32
+		err error
+	)
+
+	s, _ := NewSigner(1, []byte("0123456789abcdef"))
+	id := newID32(1337, -1)
+	if nid, err = s.ParseSigned32(s.Sign32(id)); err != nil {
+		t.Fatal(err)
+	}
+
+	if id != nid {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	// 32-bit signed IDs should not parse as 16 byte IDs
+	if _, err = s.ParseSigned(s.Sign32(id)); err != ErrInvalidLength {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidLength, err)
+	}
+
+	// The last character of a signed ID32 holds unused trailing bits, altering them must
+	// not verify
+	const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
+	signed := s.Sign32(id)
+	last := strings.IndexByte(alphabet, signed[len(signed)-1])
+	altered := signed[:len(signed)-1] + string(alphabet[last^1])
+	if _, err = s.ParseSigned32(altered); err != ErrInvalidSignature {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidSignature, err)
+	}
+
+	if err = s.Verify(signed + "\n"); err != ErrInvalidSignature {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidSignature, err)
+	}
+}
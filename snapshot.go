@@ -0,0 +1,390 @@
+package idg
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PathDNA/turtleDB"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidSnapshot is returned when a snapshot cannot be read
+	ErrInvalidSnapshot = errors.Error("invalid snapshot")
+	// ErrKeyNotInSnapshot is returned when a restored key is not within the snapshot
+	ErrKeyNotInSnapshot = errors.Error("key is not within the snapshot")
+	// ErrSafetyMarginOverflow is returned when a safety margin moves an index past the maximum index
+	ErrSafetyMarginOverflow = errors.Error("safety margin overflows index")
+)
+
+// Version of the snapshot format
+const snapshotVersion = 1
+
+// Snapshot holds the next index of each key of a set of generators
+type Snapshot struct {
+	Version int       `json:"version"`
+	Time    time.Time `json:"time"`
+	// Next index to be issued by key
+	Indexes map[string]uint64 `json:"indexes"`
+}
+
+// ReadSnapshot will read a snapshot
+// Note: Decode errors are returned as a *SnapshotError, both ErrInvalidSnapshot and the
+// decode error can be matched utilizing errors.Is and errors.As
+func ReadSnapshot(r io.Reader) (s Snapshot, err error) {
+	if err = json.NewDecoder(r).Decode(&s); err != nil {
+		err = &SnapshotError{Err: err}
+		return
+	}
+
+	if s.Version != snapshotVersion || s.Indexes == nil {
+		err = ErrInvalidSnapshot
+	}
+
+	return
+}
+
+// SnapshotError is returned when a snapshot cannot be decoded
+type SnapshotError struct {
+	// Err is the underlying decode error
+	Err error
+}
+
+// Error will return the error message
+func (e *SnapshotError) Error() string {
+	return ErrInvalidSnapshot.Error() + ": " + e.Err.Error()
+}
+
+// Unwrap will return ErrInvalidSnapshot and the underlying decode error
+func (e *SnapshotError) Unwrap() []error {
+	return []error{ErrInvalidSnapshot, e.Err}
+}
+
+// newSnapshot will return a new snapshot with the current time
+func newSnapshot() (s Snapshot) {
+	s.Version = snapshotVersion
+	s.Time = time.Now().UTC()
+	s.Indexes = make(map[string]uint64)
+	return
+}
+
+// write will write the snapshot to w
+func (s *Snapshot) write(w io.Writer) (err error) {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "\t")
+	return enc.Encode(s)
+}
+
+// RestoreOption is used to configure a restore
+type RestoreOption func(*restoreOpts)
+
+// WithForce will allow a restore to move indexes backwards
+// Note: Moving an index backwards will reissue indexes, this is intended for recovering
+// from mistakes such as an accidental bump
+func WithForce() RestoreOption {
+	return func(o *restoreOpts) {
+		o.force = true
+	}
+}
+
+// WithSafetyMargin will move restored indexes forward by n, this skips indexes which may have
+// been issued after the snapshot was taken
+func WithSafetyMargin(n uint64) RestoreOption {
+	return func(o *restoreOpts) {
+		o.margin = n
+	}
+}
+
+// restoreOpts are the configurable values of a restore
+type restoreOpts struct {
+	force  bool
+	margin uint64
+}
+
+// newRestoreOpts will return the restore options of the provided options
+func newRestoreOpts(ops []RestoreOption) (o restoreOpts) {
+	for _, fn := range ops {
+		fn(&o)
+	}
+
+	return
+}
+
+// target will return the index to restore for the provided snapshot and current indexes
+// Note: ErrIndexBackwards is returned when the target is behind the current index and the
+// restore is not forced. ok is false when the index does not need to change
+func (o *restoreOpts) target(snap, cur uint64) (idx uint64, ok bool, err error) {
+	if idx = snap + o.margin; idx < snap {
+		err = ErrSafetyMarginOverflow
+		return
+	}
+
+	switch {
+	case idx == cur:
+		return
+	case idx < cur && !o.force:
+		err = ErrIndexBackwards
+		return
+	}
+
+	ok = true
+	return
+}
+
+// Snapshot will write a snapshot of the next index of the generator to w
+func (p *PIDG) Snapshot(w io.Writer) (err error) {
+	s := newSnapshot()
+	p.mux.Read(func() {
+		s.Indexes[p.key] = p.idx
+	})
+
+	return s.write(w)
+}
+
+// Restore will restore the index of the generator from a snapshot
+// Note: Restore refuses to move the index backwards unless WithForce is provided. When
+// journaling is enabled, the index is rolled forward to the journal when it is next opened
+func (p *PIDG) Restore(r io.Reader, ops ...RestoreOption) (err error) {
+	var s Snapshot
+	if s, err = ReadSnapshot(r); err != nil {
+		return
+	}
+
+	snap, ok := s.Indexes[p.key]
+	if !ok {
+		return ErrKeyNotInSnapshot
+	}
+
+	o := newRestoreOpts(ops)
+	p.mux.Update(func() {
+		var idx uint64
+		if idx, ok, err = o.target(snap, p.idx); err != nil || !ok {
+			return
+		}
+
+		if err = p.f.write(idx); err != nil {
+			return
+		}
+
+		if err = p.f.f.Sync(); err != nil {
+			return
+		}
+
+		p.idx = idx
+	})
+
+	return
+}
+
+// SnapshotDir will write a snapshot of the persistent files within a directory to w
+// Note: Files are read without acquiring their locks so running generators can be snapshotted
+func SnapshotDir(dir string, w io.Writer) (err error) {
+	var fps []string
+	if fps, err = ListFiles(dir); err != nil {
+		return
+	}
+
+	s := newSnapshot()
+	for _, fp := range fps {
+		var idx uint64
+		if idx, err = peekFile(fp); err != nil {
+			return
+		}
+
+		s.Indexes[strings.TrimSuffix(filepath.Base(fp), FileExt)] = idx
+	}
+
+	return s.write(w)
+}
+
+// RestoreDir will restore the persistent files within a directory from a snapshot, files are
+// created for keys which do not exist
+// Note: No file is modified (or left behind when created) when any key would move backwards
+// (without WithForce) or when any file is locked by a running generator
+func RestoreDir(dir string, r io.Reader, ops ...RestoreOption) (err error) {
+	var s Snapshot
+	if s, err = ReadSnapshot(r); err != nil {
+		return
+	}
+
+	keys := make([]string, 0, len(s.Indexes))
+	for key := range s.Indexes {
+		// Keys are checked as NewPersistent does, so every snapshot of SnapshotDir can be restored
+		if !isGeneratorKey(key) {
+			return ErrInvalidSnapshot
+		}
+
+		keys = append(keys, key)
+	}
+
+	if err = os.MkdirAll(dir, 0744); err != nil {
+		return
+	}
+
+	sort.Strings(keys)
+	var (
+		files   = make([]*File, 0, len(keys))
+		created = make([]bool, 0, len(keys))
+	)
+
+	defer func() {
+		for i, f := range files {
+			f.Close()
+			if err != nil && created[i] {
+				// Remove the files created by a refused restore
+				os.Remove(f.Name())
+			}
+		}
+	}()
+
+	// Lock and validate all files prior to writing any file
+	o := newRestoreOpts(ops)
+	targets := make([]uint64, len(keys))
+	for i, key := range keys {
+		var (
+			f      *File
+			create bool
+		)
+
+		if f, create, err = openRestoreFile(filepath.Join(dir, key+FileExt)); err != nil {
+			return
+		}
+
+		files = append(files, f)
+		created = append(created, create)
+		if targets[i], _, err = o.target(s.Indexes[key], f.Index()); err != nil {
+			return
+		}
+	}
+
+	for i, f := range files {
+		if f.Format() != FileEmpty && targets[i] == f.Index() {
+			continue
+		}
+
+		if err = f.write(targets[i]); err != nil {
+			return
+		}
+
+		if err = f.f.Sync(); err != nil {
+			return
+		}
+	}
+
+	return
+}
+
+// openRestoreFile will open and lock the persistent file of a restore, created is true when
+// the file did not exist and was created by the restore
+func openRestoreFile(fp string) (f *File, created bool, err error) {
+	if f, err = openFile(fp, 0); !os.IsNotExist(err) {
+		return
+	}
+
+	// The file is created exclusively so an existing file is never mistaken for a created file
+	if f, err = openFile(fp, os.O_CREATE|os.O_EXCL); err != nil {
+		return
+	}
+
+	created = true
+	return
+}
+
+// peekFile will read the next index of a persistent file without acquiring its lock
+func peekFile(fp string) (idx uint64, err error) {
+	var f File
+	if f.f, err = os.Open(fp); err != nil {
+		return
+	}
+	defer f.f.Close()
+
+	// A torn read of a file being written fails its checksum, try again
+	for i := 0; i < 3; i++ {
+		if _, err = f.f.Seek(0, io.SeekStart); err != nil {
+			return
+		}
+
+		if err = f.read(); err != ErrFileChecksum {
+			break
+		}
+	}
+
+	return f.idx, err
+}
+
+// Snapshot will write a snapshot of the next index of every TIDG key within the transaction to w
+// Note: Unlike PIDG.Snapshot, a transaction is required as a TIDG does not hold its database
+// (e.g. utilize db.Read). Every key of the database is covered, not only the key of the TIDG
+func (t *TIDG) Snapshot(txn turtleDB.Txn, w io.Writer) (err error) {
+	s := newSnapshot()
+	var bkt turtleDB.Bucket
+	if bkt, err = txn.Get(tidgBkt); err == turtleDB.ErrKeyDoesNotExist {
+		// No index has been issued yet
+		return s.write(w)
+	} else if err != nil {
+		return
+	}
+
+	if err = bkt.ForEach(func(key string, val turtleDB.Value) (err error) {
+		idx, ok := val.(uint64)
+		if !ok {
+			return turtleDB.ErrInvalidType
+		}
+
+		s.Indexes[key] = idx
+		return
+	}); err != nil {
+		return
+	}
+
+	return s.write(w)
+}
+
+// Restore will restore the index of every key within the snapshot within the transaction
+// Note: Unlike PIDG.Restore, a transaction is required as a TIDG does not hold its database
+// (e.g. utilize db.Update). Restore refuses to move any index backwards unless WithForce is
+// provided, no index is modified when any index would move backwards
+func (t *TIDG) Restore(txn turtleDB.Txn, r io.Reader, ops ...RestoreOption) (err error) {
+	var s Snapshot
+	if s, err = ReadSnapshot(r); err != nil {
+		return
+	}
+
+	var bkt turtleDB.Bucket
+	if bkt, err = txn.Create(tidgBkt); err != nil {
+		return
+	}
+
+	o := newRestoreOpts(ops)
+	targets := make(map[string]uint64, len(s.Indexes))
+	for key, snap := range s.Indexes {
+		var cur uint64
+		if cur, err = getIndex(bkt, key); err != nil {
+			return
+		}
+
+		var (
+			idx uint64
+			ok  bool
+		)
+
+		if idx, ok, err = o.target(snap, cur); err != nil {
+			return
+		} else if ok {
+			targets[key] = idx
+		}
+	}
+
+	for key, idx := range targets {
+		if err = bkt.Put(key, idx); err != nil {
+			return
+		}
+	}
+
+	return
+}
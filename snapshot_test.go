@@ -0,0 +1,212 @@
+package idg
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"testing"
+
+	"github.com/PathDNA/turtleDB"
+)
+
+func TestPIDGSnapshot(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	p, err := NewPersistent("snapshot", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	if _, err = p.Reserve(10); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err = p.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := buf.Bytes()
+	if _, err = p.Reserve(5); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restoring would move the index backwards
+	if err = p.Restore(bytes.NewReader(snap)); err != ErrIndexBackwards {
+		t.Fatalf("invalid error, expected %v and received %v", ErrIndexBackwards, err)
+	}
+
+	if err = p.Restore(bytes.NewReader(snap), WithSafetyMargin(100)); err != nil {
+		t.Fatal(err)
+	}
+
+	var start uint64
+	if start, err = p.Reserve(1); err != nil || start != 110 {
+		t.Fatalf("invalid index, expected %d and received %d (%v)", 110, start, err)
+	}
+
+	if err = p.Restore(bytes.NewReader(snap), WithForce()); err != nil {
+		t.Fatal(err)
+	}
+
+	if start, err = p.Reserve(1); err != nil || start != 10 {
+		t.Fatalf("invalid index, expected %d and received %d (%v)", 10, start, err)
+	}
+
+	if err = p.Restore(bytes.NewReader([]byte(`{"version":1,"indexes":{"other":1}}`))); err != ErrKeyNotInSnapshot {
+		t.Fatalf("invalid error, expected %v and received %v", ErrKeyNotInSnapshot, err)
+	}
+
+	if err = p.Restore(bytes.NewReader([]byte(`{}`))); err != ErrInvalidSnapshot {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidSnapshot, err)
+	}
+
+	// Decode errors are wrapped
+	var serr *json.SyntaxError
+	if err = p.Restore(bytes.NewReader([]byte(`{"version":`))); !errors.Is(err, ErrInvalidSnapshot) || !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("invalid error, expected %v wrapping %v and received %v", ErrInvalidSnapshot, io.ErrUnexpectedEOF, err)
+	}
+
+	if err = p.Restore(bytes.NewReader([]byte(`{"version":x}`))); !errors.Is(err, ErrInvalidSnapshot) || !errors.As(err, &serr) {
+		t.Fatalf("invalid error, expected %v wrapping a syntax error and received %v", ErrInvalidSnapshot, err)
+	}
+}
+
+func TestSnapshotDir(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	a, err := NewPersistent("a", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = a.Reserve(3); err != nil {
+		t.Fatal(err)
+	}
+
+	// Running generators can be snapshotted
+	var buf bytes.Buffer
+	if err = SnapshotDir("./test_data", &buf); err != nil {
+		t.Fatal(err)
+	}
+
+	snap := buf.Bytes()
+	// Running generators cannot be restored
+	if err = RestoreDir("./test_data", bytes.NewReader(snap)); err != ErrLocked {
+		t.Fatalf("invalid error, expected %v and received %v", ErrLocked, err)
+	}
+
+	if err = a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = os.RemoveAll("./test_data"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = RestoreDir("./test_data", bytes.NewReader(snap), WithSafetyMargin(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	if a, err = NewPersistent("a", "./test_data"); err != nil {
+		t.Fatal(err)
+	}
+	defer a.Close()
+
+	var start uint64
+	if start, err = a.Reserve(1); err != nil || start != 5 {
+		t.Fatalf("invalid index, expected %d and received %d (%v)", 5, start, err)
+	}
+
+	// A refused restore does not leave created files behind, keys are validated in sorted
+	// order so 0b and 0c are created before a is refused
+	refused := []byte(`{"version":1,"indexes":{"0b":1,"0c":1,"a":1}}`)
+	if err = a.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = RestoreDir("./test_data", bytes.NewReader(refused)); err != ErrIndexBackwards {
+		t.Fatalf("invalid error, expected %v and received %v", ErrIndexBackwards, err)
+	}
+
+	var fps []string
+	if fps, err = ListFiles("./test_data"); err != nil || len(fps) != 1 {
+		t.Fatalf("invalid files: %v (%v)", fps, err)
+	}
+
+	// Keys accepted by NewPersistent can be restored, keys escaping the directory cannot
+	if err = RestoreDir("./test_data", bytes.NewReader([]byte(`{"version":1,"indexes":{"orders.v1":1}}`))); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = RestoreDir("./test_data", bytes.NewReader([]byte(`{"version":1,"indexes":{"../a":1}}`))); err != ErrInvalidSnapshot {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidSnapshot, err)
+	}
+}
+
+func TestTIDGSnapshot(t *testing.T) {
+	fm := turtleDB.FuncsMap{}
+	a := NewTIDG("a", fm)
+	b := NewTIDG("b", fm)
+
+	db, err := turtleDB.New("tidg_test", "./test_data", fm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll("./test_data")
+	defer db.Close()
+
+	var buf bytes.Buffer
+	if err = db.Update(func(txn turtleDB.Txn) (err error) {
+		if _, err = a.Reserve(txn, 3); err != nil {
+			return
+		}
+
+		if _, err = b.Reserve(txn, 7); err != nil {
+			return
+		}
+
+		return a.Snapshot(txn, &buf)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := ReadSnapshot(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.Indexes) != 2 || s.Indexes["a"] != 3 || s.Indexes["b"] != 7 {
+		t.Fatalf("invalid snapshot indexes: %v", s.Indexes)
+	}
+
+	snap := buf.Bytes()
+	if err = db.Update(func(txn turtleDB.Txn) (err error) {
+		if _, err = b.Reserve(txn, 1); err != nil {
+			return
+		}
+
+		// Restoring b would move the index backwards, a must remain untouched
+		if err = a.Restore(txn, bytes.NewReader(snap)); err != ErrIndexBackwards {
+			t.Fatalf("invalid error, expected %v and received %v", ErrIndexBackwards, err)
+		}
+
+		if err = a.Restore(txn, bytes.NewReader(snap), WithSafetyMargin(1), WithForce()); err != nil {
+			return
+		}
+
+		var start uint64
+		if start, err = a.Reserve(txn, 1); err != nil || start != 4 {
+			t.Fatalf("invalid index, expected %d and received %d (%v)", 4, start, err)
+		}
+
+		if start, err = b.Reserve(txn, 1); err != nil || start != 8 {
+			t.Fatalf("invalid index, expected %d and received %d (%v)", 8, start, err)
+		}
+
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,163 @@
+package idg
+
+import (
+	"time"
+
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidSnowflake is returned when a Snowflake layout is not valid
+	ErrInvalidSnowflake = errors.Error("invalid snowflake layout")
+	// ErrInvalidNode is returned when a node does not fit within the node bits of a Snowflake layout
+	ErrInvalidNode = errors.Error("node does not fit within the available bits")
+	// ErrTimeOverflow is returned when a timestamp does not fit within the available bits
+	ErrTimeOverflow = errors.Error("timestamp does not fit within the available bits")
+	// ErrClockRegression is returned when the system clock has moved backwards
+	ErrClockRegression = errors.Error("clock has moved backwards")
+)
+
+var (
+	// TwitterSnowflake is the layout utilized by Twitter, 41 bits of milliseconds
+	// since the Twitter epoch, 10 bits of node and 12 bits of sequence
+	TwitterSnowflake = Snowflake{
+		Epoch:        time.UnixMilli(1288834974657),
+		Unit:         time.Millisecond,
+		TimeBits:     41,
+		NodeBits:     10,
+		SequenceBits: 12,
+	}
+
+	// DiscordSnowflake is the layout utilized by Discord, milliseconds since the Discord epoch,
+	// 10 bits of node (worker and process) and 12 bits of sequence
+	// Note: Discord reserves 42 bits of time, the top bit is the sign bit of ID64 so 41 bits
+	// are utilized. IDs match Discord's until the top bit is set in 2084
+	DiscordSnowflake = Snowflake{
+		Epoch:        time.UnixMilli(1420070400000),
+		Unit:         time.Millisecond,
+		TimeBits:     41,
+		NodeBits:     10,
+		SequenceBits: 12,
+	}
+
+	// Sonyflake is the layout utilized by Sonyflake, 39 bits of 10 millisecond units
+	// since the Sonyflake epoch, 8 bits of sequence and 16 bits of node (machine)
+	Sonyflake = Snowflake{
+		Epoch:              time.Date(2014, 9, 1, 0, 0, 0, 0, time.UTC),
+		Unit:               10 * time.Millisecond,
+		TimeBits:           39,
+		NodeBits:           16,
+		SequenceBits:       8,
+		SequenceBeforeNode: true,
+	}
+)
+
+// Snowflake represents the bit layout of an ID64. From most to least significant
+// bit, an ID64 holds the time, the node and the sequence
+type Snowflake struct {
+	// Epoch is the reference time of the time bits
+	Epoch time.Time
+	// Unit is the duration represented by a single time increment
+	Unit time.Duration
+
+	// TimeBits are the number of bits utilized for the time
+	TimeBits uint8
+	// NodeBits are the number of bits utilized for the node
+	NodeBits uint8
+	// SequenceBits are the number of bits utilized for the sequence
+	SequenceBits uint8
+
+	// SequenceBeforeNode will place the sequence bits before the node bits
+	// Note: This is utilized by Sonyflake
+	SequenceBeforeNode bool
+}
+
+// validate will ensure a Snowflake layout is valid
+// Note: Layouts may utilize at most 63 bits, the top bit of an ID64 is the sign bit and
+// must remain unset so IDs are positive and sort in time order
+func (s *Snowflake) validate() (err error) {
+	switch {
+	case s.Unit <= 0:
+		return ErrInvalidSnowflake
+	case s.TimeBits == 0 || s.SequenceBits == 0:
+		return ErrInvalidSnowflake
+	case int(s.TimeBits)+int(s.NodeBits)+int(s.SequenceBits) > 63:
+		return ErrInvalidSnowflake
+	}
+
+	return
+}
+
+// nodeShift will return the bit offset of the node
+func (s *Snowflake) nodeShift() uint8 {
+	if s.SequenceBeforeNode {
+		return 0
+	}
+
+	return s.SequenceBits
+}
+
+// sequenceShift will return the bit offset of the sequence
+func (s *Snowflake) sequenceShift() uint8 {
+	if s.SequenceBeforeNode {
+		return s.NodeBits
+	}
+
+	return 0
+}
+
+// ticks will return the number of time units between the epoch and the provided time
+func (s *Snowflake) ticks(t time.Time) int64 {
+	return int64(t.Sub(s.Epoch) / s.Unit)
+}
+
+// Compose will return an ID64 composed of the provided time, node and sequence
+func (s *Snowflake) Compose(t time.Time, node, seq uint64) (id ID64, err error) {
+	if err = s.validate(); err != nil {
+		return
+	}
+
+	ticks := s.ticks(t)
+	if ticks < 0 || uint64(ticks) > mask(s.TimeBits) {
+		err = ErrTimeOverflow
+		return
+	}
+
+	if node > mask(s.NodeBits) {
+		err = ErrInvalidNode
+		return
+	}
+
+	// Sequence values are wrapped to the available bits
+	seq &= mask(s.SequenceBits)
+	v := uint64(ticks) << (s.NodeBits + s.SequenceBits)
+	v |= node << s.nodeShift()
+	v |= seq << s.sequenceShift()
+	id = ID64(v)
+	return
+}
+
+// Time will return the time.Time of an ID64
+func (s *Snowflake) Time(id ID64) (t time.Time) {
+	ticks := uint64(id) >> (s.NodeBits + s.SequenceBits)
+	return s.Epoch.Add(time.Duration(ticks) * s.Unit)
+}
+
+// Node will return the node of an ID64
+func (s *Snowflake) Node(id ID64) (node uint64) {
+	return uint64(id) >> s.nodeShift() & mask(s.NodeBits)
+}
+
+// Sequence will return the sequence of an ID64
+func (s *Snowflake) Sequence(id ID64) (seq uint64) {
+	return uint64(id) >> s.sequenceShift() & mask(s.SequenceBits)
+}
+
+// mask will return a bitmask of the provided size
+func mask(bits uint8) uint64 {
+	if bits >= 64 {
+		return 1<<64 - 1
+	}
+
+	return 1<<bits - 1
+}
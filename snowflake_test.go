@@ -0,0 +1,146 @@
+package idg
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestSnowflakeCompose(t *testing.T) {
+	layouts := []Snowflake{TwitterSnowflake, DiscordSnowflake, Sonyflake}
+	now := time.Now()
+	for _, sf := range layouts {
+		id, err := sf.Compose(now, 7, 3)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if tt := sf.Time(id); now.Sub(tt) >= sf.Unit || tt.After(now) {
+			t.Fatalf("invalid time, expected %v and received %v", now, tt)
+		}
+
+		if node := sf.Node(id); node != 7 {
+			t.Fatalf("invalid node, expected %d and received %d", 7, node)
+		}
+
+		if seq := sf.Sequence(id); seq != 3 {
+			t.Fatalf("invalid sequence, expected %d and received %d", 3, seq)
+		}
+	}
+
+	// Example from the Discord documentation (worker 1, process 0, increment 7)
+	did := ID64(175928847299117063)
+	if ms := DiscordSnowflake.Time(did).UnixMilli(); ms != 1462015105796 {
+		t.Fatalf("invalid time, expected %d and received %d", 1462015105796, ms)
+	}
+
+	if node := DiscordSnowflake.Node(did); node != 1<<5 {
+		t.Fatalf("invalid node, expected %d and received %d", 1<<5, node)
+	}
+
+	if seq := DiscordSnowflake.Sequence(did); seq != 7 {
+		t.Fatalf("invalid sequence, expected %d and received %d", 7, seq)
+	}
+
+	if _, err := TwitterSnowflake.Compose(now, 1<<10, 0); err != ErrInvalidNode {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidNode, err)
+	}
+
+	if _, err := TwitterSnowflake.Compose(TwitterSnowflake.Epoch.Add(-time.Second), 0, 0); err != ErrTimeOverflow {
+		t.Fatalf("invalid error, expected %v and received %v", ErrTimeOverflow, err)
+	}
+}
+
+func TestSnowflakeValidate(t *testing.T) {
+	for _, sf := range []Snowflake{TwitterSnowflake, DiscordSnowflake, Sonyflake} {
+		if err := sf.validate(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 64 bits would utilize the sign bit of ID64
+	sf := Snowflake{Epoch: time.Unix(0, 0), Unit: time.Millisecond, TimeBits: 42, NodeBits: 10, SequenceBits: 12}
+	if err := sf.validate(); err != ErrInvalidSnowflake {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidSnowflake, err)
+	}
+
+	if _, err := NewSnowflake(0, sf); err != ErrInvalidSnowflake {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidSnowflake, err)
+	}
+
+	sf.TimeBits = 41
+	if err := sf.validate(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSIDGExhaustion(t *testing.T) {
+	var (
+		sidg *SIDG
+		last ID64
+		err  error
+	)
+
+	// Utilize a layout with only 4 sequence values per 10 milliseconds
+	sf := Snowflake{Epoch: time.Unix(0, 0), Unit: 10 * time.Millisecond, TimeBits: 40, NodeBits: 4, SequenceBits: 2}
+	if sidg, err = NewSnowflake(3, sf); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 32; i++ {
+		var id ID64
+		if id, err = sidg.Next(); err != nil {
+			t.Fatal(err)
+		}
+
+		if id <= last {
+			t.Fatalf("IDs are not increasing: %d / %d", last, id)
+		}
+
+		if node := sf.Node(id); node != 3 {
+			t.Fatalf("invalid node, expected %d and received %d", 3, node)
+		}
+
+		last = id
+	}
+
+	if _, err = NewSnowflake(16, sf); err != ErrInvalidNode {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidNode, err)
+	}
+}
+
+func TestID64JSON(t *testing.T) {
+	var (
+		b   []byte
+		nid ID64
+		err error
+	)
+
+	id := ID64(287216937340047360)
+	if b, err = json.Marshal(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(b) != "287216937340047360" {
+		t.Fatalf("invalid JSON, expected a number and received %s", b)
+	}
+
+	if err = json.Unmarshal(b, &nid); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %d / %d", id, nid)
+	}
+
+	// Strings should be accepted as well
+	if err = json.Unmarshal([]byte(`"287216937340047360"`), &nid); err != nil {
+		t.Fatal(err)
+	} else if nid != id {
+		t.Fatalf("ID's do not match: %d / %d", id, nid)
+	}
+
+	if err = nid.Scan(int64(42)); err != nil {
+		t.Fatal(err)
+	} else if nid != 42 {
+		t.Fatalf("invalid ID, expected %d and received %d", 42, nid)
+	}
+}
@@ -1,7 +1,9 @@
 package idg
 
 import (
+	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/PathDNA/turtleDB"
 	"github.com/itsmontoya/mum"
@@ -10,14 +12,20 @@ import (
 const tidgBkt = "__idg"
 
 // NewTIDG will return a new turtleDB-backed ID generator
-func NewTIDG(key string, fm turtleDB.FuncsMap) (t TIDG) {
+func NewTIDG(key string, fm turtleDB.FuncsMap, ops ...Option) (t TIDG) {
+	t.opts.apply(ops)
 	t.key = key
+	t.opts.key = key
 	fm.Put(tidgBkt, marshalIndex, unmarshalIndex)
+	// The index is held within the database and is not known until the first transaction
+	t.opened(0)
 	return
 }
 
 // TIDG is a persistent turtleDB-based ID generator
 type TIDG struct {
+	opts
+
 	// Helper for binary encoding
 	bw mum.BinaryWriter
 	// Key utilized for the index value
@@ -25,9 +33,14 @@ type TIDG struct {
 }
 
 func (t *TIDG) getIndex(bkt turtleDB.Bucket) (idx uint64, err error) {
-	var val turtleDB.Value
 	// Get value set for our TIDG.key
-	if val, err = bkt.Get(t.key); err != nil {
+	return getIndex(bkt, t.key)
+}
+
+// getIndex will return the index of the provided key
+func getIndex(bkt turtleDB.Bucket, key string) (idx uint64, err error) {
+	var val turtleDB.Value
+	if val, err = bkt.Get(key); err != nil {
 		if err == turtleDB.ErrKeyDoesNotExist {
 			// If the key does not exist, we can set error to nil
 			// An index of 0 will be just as intended
@@ -49,27 +62,83 @@ func (t *TIDG) getIndex(bkt turtleDB.Bucket) (idx uint64, err error) {
 
 // Next will return the next id
 func (t *TIDG) Next(txn turtleDB.Txn) (id ID, err error) {
+	return t.NextContext(context.Background(), txn)
+}
+
+// NextContext will return the next id, spans are parented to the provided context
+func (t *TIDG) NextContext(ctx context.Context, txn turtleDB.Txn) (id ID, err error) {
+	var idx uint64
+	if idx, err = t.reserve(ctx, txn, 1); err != nil {
+		return
+	}
+
+	id = t.newID(idx)
+	return
+}
+
+// Reserve will reserve a contiguous block of n indexes and return the first index of the block
+// Note: ErrIndexExhausted is returned when the block would wrap past the last index
+func (t *TIDG) Reserve(txn turtleDB.Txn, n uint64) (start uint64, err error) {
+	return t.ReserveContext(context.Background(), txn, n)
+}
+
+// ReserveContext will reserve a contiguous block of n indexes, spans are parented to the provided context
+func (t *TIDG) ReserveContext(ctx context.Context, txn turtleDB.Txn, n uint64) (start uint64, err error) {
+	if n == 0 {
+		err = ErrInvalidBlockSize
+		return
+	}
+
+	return t.reserve(ctx, txn, n)
+}
+
+// reserve will reserve a block of n indexes within the provided transaction
+func (t *TIDG) reserve(ctx context.Context, txn turtleDB.Txn, n uint64) (start uint64, err error) {
+	ctx, span := t.startSpan(ctx, spanReserve, backendTurtleDB, n)
+	defer func() { endSpan(span, err) }()
+
 	var bkt turtleDB.Bucket
 	// Ensure idg bucket exists
-	if bkt, err = txn.Create("__idg"); err != nil {
+	if bkt, err = txn.Create(tidgBkt); err != nil {
 		// Error encountered while creating idg bucket
 		return
 	}
 
-	var idx uint64
 	// Get current index
-	if idx, err = t.getIndex(bkt); err != nil {
+	if start, err = t.getIndex(bkt); err != nil {
 		// We encountered an error while getting, return
 		return
 	}
 
-	// Increment index value and set it as the index for our TIDG.key
-	if err = bkt.Put(t.key, idx+1); err != nil {
-		// We encountered an error while putting, return
+	if start = t.blockStart(start, n); start+n < start {
+		// The block would wrap past the last index
+		t.overflowed(start)
+		err = ErrIndexExhausted
 		return
 	}
 
-	id = newID(idx, -1)
+	// Set the index following the end of the block as the index for our TIDG.key
+	_, pspan := t.startSpan(ctx, spanPersist, backendTurtleDB, n)
+	var pstart time.Time
+	if t.metrics != nil {
+		pstart = time.Now()
+	}
+
+	err = bkt.Put(t.key, start+n)
+	t.persisted(pstart, err)
+	if err != nil {
+		// We encountered an error while putting, return
+		t.persistFailed(start+n, err)
+	}
+
+	endSpan(pspan, err)
+	return
+}
+
+// Close will report the generator as closed
+// Note: The database is owned by the caller and is not closed
+func (t *TIDG) Close() (err error) {
+	t.closed(nil)
 	return
 }
 
@@ -0,0 +1,234 @@
+package idg
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/PathDNA/atoms"
+	"github.com/itsmontoya/mum"
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidInterval is returned when a checkpoint interval is not positive
+	ErrInvalidInterval = errors.Error("invalid checkpoint interval, the interval must be positive")
+	// ErrInvalidLimit is returned when a checkpoint limit is negative
+	ErrInvalidLimit = errors.Error("invalid checkpoint limit, the limit must not be negative")
+)
+
+const (
+	// DefaultCheckpointLimit is the number of checkpoints retained when no limit is provided,
+	// one week of checkpoints at an interval of one minute
+	DefaultCheckpointLimit = 7 * 24 * 60
+)
+
+// MinIDForTime will return the lowest UUIDv7 layout ID for the provided time
+// Note: This is only meaningful for time-first (LayoutUUIDv7) IDs, which sort by
+// time when compared as bytes (e.g. native uuid columns). IDs created between T1
+// and T2 are within [MinIDForTime(T1), MaxIDForTime(T2)]
+func MinIDForTime(t time.Time) (id ID) {
+	return newUUIDv7(0, t.UnixMilli())
+}
+
+// MaxIDForTime will return the highest UUIDv7 layout ID for the provided time
+// Note: See MinIDForTime
+func MaxIDForTime(t time.Time) (id ID) {
+	return newUUIDv7(math.MaxUint64, t.UnixMilli())
+}
+
+// WithCheckpoints will set the checkpoint log of a generator
+// Note: Checkpoints allow translating time ranges into index ranges for index-first IDs. A
+// checkpoint log must not be shared between generators, the indexes of the generators would
+// be mixed within the log. Registries reject checkpoints as their options apply to every key
+func WithCheckpoints(c *Checkpoints) Option {
+	return func(o *opts) {
+		o.checkpoints = c
+	}
+}
+
+// NewCheckpoints will return a new checkpoint log which records at most one checkpoint per
+// interval and retains at most limit checkpoints, the oldest checkpoints are pruned first
+// Note: DefaultCheckpointLimit is utilized when the limit is zero
+func NewCheckpoints(interval time.Duration, limit int) (c *Checkpoints, err error) {
+	if interval <= 0 {
+		err = ErrInvalidInterval
+		return
+	}
+
+	if limit < 0 {
+		err = ErrInvalidLimit
+		return
+	}
+
+	var cp Checkpoints
+	cp.interval = interval
+	cp.limit = limit
+	c = &cp
+	return
+}
+
+// Checkpoint is a point in time and the index which was being issued at that time
+type Checkpoint struct {
+	Time  time.Time
+	Index uint64
+}
+
+// Checkpoints is a sparse time to index log kept by a generator. Index-first IDs are
+// ordered by index, so the log is utilized to translate a time range into an index range
+type Checkpoints struct {
+	mux atoms.Mux
+	// Minimum duration between checkpoints
+	interval time.Duration
+	// Maximum number of retained checkpoints, zero for DefaultCheckpointLimit
+	limit int
+	// Earliest time (Unix nanoseconds) the next checkpoint can be recorded
+	next atoms.Int64
+	// Checkpoints in ascending order
+	entries []Checkpoint
+}
+
+// record will record a checkpoint for the provided index if the interval has passed
+func (c *Checkpoints) record(idx uint64) {
+	now := time.Now()
+	if now.UnixNano() < c.next.Load() {
+		// Interval has not passed, avoid locking
+		return
+	}
+
+	c.mux.Update(func() {
+		if now.UnixNano() < c.next.Load() {
+			// Another checkpoint was recorded while we were waiting for the lock
+			return
+		}
+
+		if n := len(c.entries) - c.maxEntries() + 1; n > 0 {
+			// Prune the oldest checkpoints, ranges prior to the first checkpoint start at zero
+			c.entries = append(c.entries[:0], c.entries[n:]...)
+		}
+
+		c.entries = append(c.entries, Checkpoint{Time: now, Index: idx})
+		c.next.Store(now.Add(c.interval).UnixNano())
+	})
+}
+
+// maxEntries will return the maximum number of retained checkpoints
+func (c *Checkpoints) maxEntries() int {
+	if c.limit == 0 {
+		return DefaultCheckpointLimit
+	}
+
+	return c.limit
+}
+
+// List will return a copy of the recorded checkpoints
+func (c *Checkpoints) List() (out []Checkpoint) {
+	c.mux.Read(func() {
+		out = append(out, c.entries...)
+	})
+
+	return
+}
+
+// Trim will remove all checkpoints recorded before the provided time
+func (c *Checkpoints) Trim(before time.Time) {
+	c.mux.Update(func() {
+		i := sort.Search(len(c.entries), func(i int) bool {
+			return !c.entries[i].Time.Before(before)
+		})
+
+		c.entries = append(c.entries[:0], c.entries[i:]...)
+	})
+}
+
+// IndexRange will return the index range [lo, hi) of IDs created between start and end
+// Note: The range is conservative and may include IDs created up to one checkpoint interval
+// outside of the time range. When no checkpoint follows end, hi is set to math.MaxUint64
+func (c *Checkpoints) IndexRange(start, end time.Time) (lo, hi uint64) {
+	// ID timestamps are truncated to seconds, widen the range accordingly
+	start = start.Truncate(time.Second)
+	end = end.Truncate(time.Second).Add(time.Second)
+	hi = math.MaxUint64
+	c.mux.Read(func() {
+		// Index of the first checkpoint at or after start
+		i := sort.Search(len(c.entries), func(i int) bool {
+			return !c.entries[i].Time.Before(start)
+		})
+
+		if i > 0 {
+			// The preceding checkpoint was issuing an index before start
+			lo = c.entries[i-1].Index
+		}
+
+		// Index of the first checkpoint after end
+		j := sort.Search(len(c.entries), func(i int) bool {
+			return c.entries[i].Time.After(end)
+		})
+
+		if j < len(c.entries) {
+			hi = c.entries[j].Index + 1
+		}
+	})
+
+	return
+}
+
+// MarshalBinary is a binary encoding helper func
+func (c *Checkpoints) MarshalBinary() (out []byte, err error) {
+	// Helper for binary encoding
+	var bw mum.BinaryWriter
+	c.mux.Read(func() {
+		out = make([]byte, 0, 8+len(c.entries)*16)
+		out = append(out, bw.Int64(int64(c.interval))...)
+		for _, cp := range c.entries {
+			out = append(out, bw.Int64(cp.Time.UnixNano())...)
+			out = append(out, bw.Uint64(cp.Index)...)
+		}
+	})
+
+	return
+}
+
+// UnmarshalBinary is a binary decoding helper func
+func (c *Checkpoints) UnmarshalBinary(in []byte) (err error) {
+	if len(in) < 8 || (len(in)-8)%16 != 0 {
+		return ErrInvalidLength
+	}
+
+	// Helper for binary decoding
+	var br mum.BinaryReader
+	var interval int64
+	if interval, err = br.Int64(in[:8]); err != nil {
+		return
+	} else if interval <= 0 {
+		return ErrInvalidInterval
+	}
+
+	entries := make([]Checkpoint, 0, (len(in)-8)/16)
+	for i := 8; i < len(in); i += 16 {
+		var cp Checkpoint
+		var ns int64
+		if ns, err = br.Int64(in[i : i+8]); err != nil {
+			return
+		}
+
+		if cp.Index, err = br.Uint64(in[i+8 : i+16]); err != nil {
+			return
+		}
+
+		cp.Time = time.Unix(0, ns)
+		entries = append(entries, cp)
+	}
+
+	c.mux.Update(func() {
+		if n := len(entries) - c.maxEntries(); n > 0 {
+			// Retain the most recent checkpoints within the limit
+			entries = entries[n:]
+		}
+
+		c.interval = time.Duration(interval)
+		c.entries = entries
+	})
+
+	return
+}
@@ -0,0 +1,113 @@
+package idg
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestIDForTime(t *testing.T) {
+	start := time.UnixMilli(1700000000000)
+	end := start.Add(time.Minute)
+	lo, hi := MinIDForTime(start), MaxIDForTime(end)
+	ids := []ID{newUUIDv7(0, start.UnixMilli()), newUUIDv7(math.MaxUint64, end.UnixMilli()), newUUIDv7(1337, start.Add(time.Second).UnixMilli())}
+	for _, id := range ids {
+		if bytes.Compare(id[:], lo[:]) < 0 || bytes.Compare(id[:], hi[:]) > 0 {
+			t.Fatalf("ID is outside of bounds: %v", id)
+		}
+	}
+
+	outside := []ID{newUUIDv7(math.MaxUint64, start.UnixMilli()-1), newUUIDv7(0, end.UnixMilli()+1)}
+	for _, id := range outside {
+		if bytes.Compare(id[:], lo[:]) >= 0 && bytes.Compare(id[:], hi[:]) <= 0 {
+			t.Fatalf("ID is within bounds: %v", id)
+		}
+	}
+}
+
+func TestCheckpoints(t *testing.T) {
+	c, err := NewCheckpoints(time.Second, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	base := time.Unix(1700000000, 0)
+	// Simulate checkpoints recorded every 10 seconds with 100 IDs per second
+	for i := 0; i < 10; i++ {
+		c.entries = append(c.entries, Checkpoint{Time: base.Add(time.Duration(i) * 10 * time.Second), Index: uint64(i * 1000)})
+	}
+
+	lo, hi := c.IndexRange(base.Add(25*time.Second), base.Add(45*time.Second))
+	if lo != 2000 || hi != 5001 {
+		t.Fatalf("invalid range, expected [%d, %d) and received [%d, %d)", 2000, 5001, lo, hi)
+	}
+
+	if lo, hi = c.IndexRange(base.Add(-time.Hour), base.Add(time.Hour)); lo != 0 || hi != math.MaxUint64 {
+		t.Fatalf("invalid range, expected [%d, %d) and received [%d, %d)", 0, uint64(math.MaxUint64), lo, hi)
+	}
+
+	var b []byte
+	if b, err = c.MarshalBinary(); err != nil {
+		t.Fatal(err)
+	}
+
+	var nc Checkpoints
+	if err = nc.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+
+	if list := nc.List(); len(list) != 10 || !list[3].Time.Equal(c.entries[3].Time) || list[3].Index != 3000 {
+		t.Fatalf("invalid checkpoints: %v", list)
+	}
+
+	c.Trim(base.Add(50 * time.Second))
+	if list := c.List(); len(list) != 5 || list[0].Index != 5000 {
+		t.Fatalf("invalid checkpoints after trim: %v", list)
+	}
+}
+
+func TestCheckpointsLimit(t *testing.T) {
+	if _, err := NewCheckpoints(0, 0); err != ErrInvalidInterval {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidInterval, err)
+	}
+
+	if _, err := NewCheckpoints(time.Second, -1); err != ErrInvalidLimit {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidLimit, err)
+	}
+
+	c, err := NewCheckpoints(time.Nanosecond, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 10; i++ {
+		// Ensure the interval has passed between checkpoints
+		c.next.Store(0)
+		c.record(uint64(i))
+	}
+
+	// The oldest checkpoints are pruned
+	if list := c.List(); len(list) != 3 || list[0].Index != 7 || list[2].Index != 9 {
+		t.Fatalf("invalid checkpoints: %v", list)
+	}
+
+	if lo, _ := c.IndexRange(time.Unix(0, 0), time.Now()); lo != 0 {
+		t.Fatalf("invalid range start, expected %d and received %d", 0, lo)
+	}
+}
+
+func TestGeneratorCheckpoints(t *testing.T) {
+	c, err := NewCheckpoints(time.Hour, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idg := New(1337, WithCheckpoints(c))
+	idg.Next()
+	idg.Next()
+	// Only one checkpoint should be recorded within the interval
+	if list := c.List(); len(list) != 1 || list[0].Index != 1337 {
+		t.Fatalf("invalid checkpoints: %v", list)
+	}
+}
@@ -0,0 +1,73 @@
+package idg
+
+import (
+	"context"
+)
+
+const (
+	// Span names
+	spanReserve = "idg.reserve"
+	spanPersist = "idg.persist"
+
+	// Backends reported by the span attributes
+	backendFile     = "file"
+	backendTurtleDB = "turtledb"
+)
+
+// Span returned when tracing is disabled
+var noopSpan Span = nopSpan{}
+
+// Tracer starts spans around index reservation and persistence, see the idgotel package
+// for an OpenTelemetry implementation
+// Note: Implementations must be safe for concurrent use
+type Tracer interface {
+	// Start will start a span with the provided name (idg.reserve or idg.persist), spans
+	// started with the returned context are children of the span
+	Start(ctx context.Context, name string, attrs SpanAttributes) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer
+type Span interface {
+	// End will end the span, err is set when the operation of the span failed
+	End(err error)
+}
+
+// SpanAttributes are the attributes of a span
+type SpanAttributes struct {
+	// Key of the generator
+	Key string
+	// Backend of the generator, "file" or "turtledb"
+	Backend string
+	// Number of indexes reserved or persisted
+	BlockSize uint64
+}
+
+// WithTracer will enable spans around index reservation (idg.reserve) and persistence
+// (idg.persist) of PIDG, TIDG and registry files
+// Note: Tracing is disabled by default, utilize the Context variants (e.g. PIDG.NextContext)
+// to parent spans to a request
+func WithTracer(tr Tracer) Option {
+	return func(o *opts) {
+		o.tracer = tr
+	}
+}
+
+// startSpan will start a span when tracing is enabled, a no-op span is returned otherwise
+func (o *opts) startSpan(ctx context.Context, name, backend string, n uint64) (context.Context, Span) {
+	if o.tracer == nil {
+		return ctx, noopSpan
+	}
+
+	return o.tracer.Start(ctx, name, SpanAttributes{Key: o.key, Backend: backend, BlockSize: n})
+}
+
+// endSpan will end a span, recording the error when set
+func endSpan(span Span, err error) {
+	span.End(err)
+}
+
+// nopSpan is a no-op implementation of Span
+type nopSpan struct{}
+
+// End is a no-op
+func (nopSpan) End(err error) {}
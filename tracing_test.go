@@ -0,0 +1,144 @@
+package idg
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/PathDNA/turtleDB"
+)
+
+func TestPIDGTracing(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	tr := &testTracer{}
+	p, err := NewPersistent("traced", "./test_data", WithTracer(tr))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	ctx := context.WithValue(context.Background(), testSpanKey{}, "request")
+	if _, err = p.ReserveContext(ctx, 10); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []testSpan{
+		{name: spanReserve, parent: "request", attrs: SpanAttributes{Key: "traced", Backend: backendFile, BlockSize: 10}},
+		{name: spanPersist, parent: spanReserve, attrs: SpanAttributes{Key: "traced", Backend: backendFile, BlockSize: 10}},
+	}
+
+	tr.test(t, expected)
+
+	// Inject a read-only handle of the file to force a persist error
+	rw := p.f.f
+	if p.f.f, err = os.Open(rw.Name()); err != nil {
+		t.Fatal(err)
+	}
+	defer rw.Close()
+
+	tr.reset()
+	if _, err = p.Next(); err == nil {
+		t.Fatal("expected persist error")
+	}
+
+	for _, s := range tr.spans {
+		if s.err == nil {
+			t.Fatalf("span %s did not record the error", s.name)
+		}
+	}
+}
+
+func TestTIDGTracing(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	tr := &testTracer{}
+	fm := turtleDB.FuncsMap{}
+	tidg := NewTIDG("traced", fm, WithTracer(tr))
+	db, err := turtleDB.New("tidg_trace_test", "./test_data", fm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	if err = db.Update(func(txn turtleDB.Txn) (err error) {
+		_, err = tidg.Next(txn)
+		return
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []testSpan{
+		{name: spanReserve, attrs: SpanAttributes{Key: "traced", Backend: backendTurtleDB, BlockSize: 1}},
+		{name: spanPersist, parent: spanReserve, attrs: SpanAttributes{Key: "traced", Backend: backendTurtleDB, BlockSize: 1}},
+	}
+
+	tr.test(t, expected)
+}
+
+func TestTracingDisabled(t *testing.T) {
+	defer os.RemoveAll("./test_data")
+	p, err := NewPersistent("untraced", "./test_data")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer p.Close()
+
+	allocs := testing.AllocsPerRun(100, func() {
+		p.Next()
+	})
+
+	if allocs != 0 {
+		t.Fatalf("invalid number of allocations, expected %d and received %v", 0, allocs)
+	}
+}
+
+// testSpanKey is the context key of the name of the current test span
+type testSpanKey struct{}
+
+type testTracer struct {
+	mux   sync.Mutex
+	spans []*testSpan
+}
+
+func (tr *testTracer) Start(ctx context.Context, name string, attrs SpanAttributes) (context.Context, Span) {
+	s := &testSpan{name: name, attrs: attrs}
+	s.parent, _ = ctx.Value(testSpanKey{}).(string)
+
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+	tr.spans = append(tr.spans, s)
+	return context.WithValue(ctx, testSpanKey{}, name), s
+}
+
+func (tr *testTracer) reset() {
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+	tr.spans = nil
+}
+
+func (tr *testTracer) test(t *testing.T, expected []testSpan) {
+	tr.mux.Lock()
+	defer tr.mux.Unlock()
+	if len(tr.spans) != len(expected) {
+		t.Fatalf("invalid number of spans, expected %d and received %d", len(expected), len(tr.spans))
+	}
+
+	for i, s := range tr.spans {
+		if s.name != expected[i].name || s.parent != expected[i].parent || s.attrs != expected[i].attrs || !s.ended {
+			t.Fatalf("invalid span %d, expected %+v and received %+v", i, expected[i], *s)
+		}
+	}
+}
+
+type testSpan struct {
+	name   string
+	parent string
+	attrs  SpanAttributes
+	ended  bool
+	err    error
+}
+
+func (s *testSpan) End(err error) {
+	s.ended = true
+	s.err = err
+}
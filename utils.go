@@ -5,3 +5,23 @@ func Parse(in string) (id ID, err error) {
 	err = id.parse([]byte(in))
 	return
 }
+
+// ParseBytes will parse a byteslice id
+// Note: Unlike Parse, no allocations are made
+func ParseBytes(in []byte) (id ID, err error) {
+	err = id.parse(in)
+	return
+}
+
+// Parse32 will parse a 32-bit string id
+func Parse32(in string) (id ID32, err error) {
+	err = id.parse([]byte(in))
+	return
+}
+
+// ParseBytes32 will parse a 32-bit byteslice id
+// Note: Unlike Parse32, no allocations are made
+func ParseBytes32(in []byte) (id ID32, err error) {
+	err = id.parse(in)
+	return
+}
@@ -0,0 +1,165 @@
+package idg
+
+import (
+	"encoding/hex"
+	"time"
+
+	"github.com/missionMeteora/toolkit/errors"
+)
+
+const (
+	// ErrInvalidUUID is returned when a UUID string cannot be parsed
+	ErrInvalidUUID = errors.Error("invalid UUID")
+)
+
+const (
+	// UUID version (7) stored within the high nibble of byte 6
+	uuidVersion = 0x70
+	// RFC 9562 variant (0b10) stored within the high bits of byte 8
+	uuidVariant = 0x80
+	// Length of a canonical UUID string (8-4-4-4-12)
+	uuidStrLen = 36
+)
+
+// newUUIDv7 will return a new UUIDv7 formatted ID with the provided index and timestamp
+// Note: If timestamp is set to -1, the current Unix timestamp will be utilized. Unlike
+// newID, the timestamp is represented in milliseconds as required by RFC 9562
+func newUUIDv7(idx uint64, ms int64) (id ID) {
+	if ms == -1 {
+		ms = time.Now().UnixMilli()
+	}
+
+	// Set the 48-bit big-endian timestamp within the first 6 bytes
+	id[0] = byte(ms >> 40)
+	id[1] = byte(ms >> 32)
+	id[2] = byte(ms >> 24)
+	id[3] = byte(ms >> 16)
+	id[4] = byte(ms >> 8)
+	id[5] = byte(ms)
+	// The 74 bits of rand_a and rand_b hold the big-endian index, the upper
+	// 10 bits are left empty. This keeps the IDs byte-sortable by time, then index
+	id[6] = uuidVersion
+	id[7] = byte(idx >> 62)
+	id[8] = uuidVariant | byte(idx>>56)&0x3f
+	id[9] = byte(idx >> 48)
+	id[10] = byte(idx >> 40)
+	id[11] = byte(idx >> 32)
+	id[12] = byte(idx >> 24)
+	id[13] = byte(idx >> 16)
+	id[14] = byte(idx >> 8)
+	id[15] = byte(idx)
+	return
+}
+
+// isUUIDv7 will return whether or not the provided ID utilizes the UUIDv7 layout
+// Note: Within an index-first ID, bytes 6 and 7 are the upper bytes of the index and byte 8
+// is the low byte of the timestamp. An index-first ID is mistaken for a UUIDv7 ID when byte 6
+// of its index is 0x70, the upper 6 bits of byte 7 are zero and the timestamp has the variant
+// bits (one second in four). Generators utilizing the index-first layout skip these indexes,
+// see isAmbiguousIndex
+func isUUIDv7(id *ID) bool {
+	return id[6] == uuidVersion && id[7]&0xfc == 0 && id[8]&0xc0 == uuidVariant
+}
+
+// isAmbiguousIndex will return whether or not an index-first ID of the provided index may be
+// mistaken for a UUIDv7 ID, see isUUIDv7
+// Note: Ambiguous indexes form four ranges of 2^48 indexes, the first starting at 0x70<<48
+func isAmbiguousIndex(idx uint64) bool {
+	return byte(idx>>48) == uuidVersion && idx>>58 == 0
+}
+
+// ambiguousEnd will return the index following the ambiguous range of the provided index
+func ambiguousEnd(idx uint64) uint64 {
+	return (idx>>48 + 1) << 48
+}
+
+// skipAmbiguous will return the start of a block of n indexes, at or after the provided
+// start, which does not hold an ambiguous index
+func skipAmbiguous(start, n uint64) uint64 {
+	for b := uint64(0); b < 4; b++ {
+		// Bounds of the ambiguous range with an upper byte of b
+		lo := b<<56 | uuidVersion<<48
+		hi := ambiguousEnd(lo)
+		if start < hi && start+(n-1) >= lo {
+			start = hi
+		}
+	}
+
+	return start
+}
+
+// uuidV7Index will return the index stored within a UUIDv7 formatted ID
+func uuidV7Index(id *ID) (idx uint64) {
+	idx = uint64(id[7]) << 62
+	idx |= uint64(id[8]&0x3f) << 56
+	// Bytes 9 through 15 are the remaining 56 bits of the index
+	for i := 9; i < 16; i++ {
+		idx |= uint64(id[i]) << (8 * uint(15-i))
+	}
+
+	return
+}
+
+// uuidV7Time will return the time stored within a UUIDv7 formatted ID
+func uuidV7Time(id *ID) (t time.Time) {
+	var ms int64
+	for _, b := range id[:6] {
+		ms = ms<<8 | int64(b)
+	}
+
+	return time.UnixMilli(ms)
+}
+
+// Layout will return the layout of an ID
+func (id *ID) Layout() (l Layout) {
+	if id != nil && isUUIDv7(id) {
+		return LayoutUUIDv7
+	}
+
+	return LayoutIndexFirst
+}
+
+// UUIDString will return the canonical RFC 9562 string representation of an ID
+// (e.g. 01890a5d-ac96-7000-8000-000000000539)
+func (id *ID) UUIDString() (out string) {
+	if id == nil {
+		return
+	}
+
+	var buf [uuidStrLen]byte
+	hex.Encode(buf[0:8], id[0:4])
+	buf[8] = '-'
+	hex.Encode(buf[9:13], id[4:6])
+	buf[13] = '-'
+	hex.Encode(buf[14:18], id[6:8])
+	buf[18] = '-'
+	hex.Encode(buf[19:23], id[8:10])
+	buf[23] = '-'
+	hex.Encode(buf[24:], id[10:])
+	return string(buf[:])
+}
+
+// ParseUUID will parse a canonical RFC 9562 UUID string
+// Note: Any UUID will be accepted, UUIDs which were not created with LayoutUUIDv7
+// will be treated as index-first IDs
+func ParseUUID(in string) (id ID, err error) {
+	if len(in) != uuidStrLen || in[8] != '-' || in[13] != '-' || in[18] != '-' || in[23] != '-' {
+		err = ErrInvalidUUID
+		return
+	}
+
+	// Start and end positions of each hex group within the string
+	groups := [5][2]int{{0, 8}, {9, 13}, {14, 18}, {19, 23}, {24, 36}}
+	var n int
+	for _, g := range groups {
+		var decoded int
+		if decoded, err = hex.Decode(id[n:], []byte(in[g[0]:g[1]])); err != nil {
+			err = ErrInvalidUUID
+			return
+		}
+
+		n += decoded
+	}
+
+	return
+}
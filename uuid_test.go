@@ -0,0 +1,125 @@
+package idg
+
+import (
+	"testing"
+	"time"
+)
+
+func TestUUIDv7(t *testing.T) {
+	var (
+		idx uint64
+		tt  time.Time
+		err error
+	)
+
+	now := time.Now().Truncate(time.Millisecond)
+	// Initialize a generator which produces UUIDv7 formatted IDs
+	idg := New(1337, WithLayout(LayoutUUIDv7))
+	id := idg.Next()
+	// Ensure version and variant bits are set
+	if id[6]>>4 != 7 {
+		t.Fatalf("invalid version, expected 7 and received %d", id[6]>>4)
+	}
+
+	if id[8]>>6 != 2 {
+		t.Fatalf("invalid variant, expected 2 and received %d", id[8]>>6)
+	}
+
+	if l := id.Layout(); l != LayoutUUIDv7 {
+		t.Fatalf("invalid layout, expected %v and received %v", LayoutUUIDv7, l)
+	}
+
+	if idx, err = id.Index(); err != nil {
+		t.Fatal(err)
+	} else if idx != 1337 {
+		t.Fatalf("invalid index, expected %d and received %d", 1337, idx)
+	}
+
+	if tt, err = id.Time(); err != nil {
+		t.Fatal(err)
+	} else if tt.Before(now) {
+		t.Fatalf("invalid time, should not be before initial timestamp: %v / %v", now, tt)
+	}
+}
+
+func TestUUIDv7Index(t *testing.T) {
+	indexes := []uint64{0, 1, 1 << 32, 1<<62 + 7, 1<<64 - 1}
+	for _, expected := range indexes {
+		id := newUUIDv7(expected, 1700000000000)
+		if err := testIndex(id, expected); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestAmbiguousIndex(t *testing.T) {
+	const lo = uint64(uuidVersion) << 48
+	if isAmbiguousIndex(lo-1) || !isAmbiguousIndex(lo) || !isAmbiguousIndex(3<<56|lo) || isAmbiguousIndex(4<<56|lo) {
+		t.Fatal("invalid ambiguous index detection")
+	}
+
+	blocks := []struct {
+		start, n, expected uint64
+	}{
+		{0, 10, 0},
+		{lo - 10, 10, lo - 10},
+		{lo - 10, 11, lo + 1<<48},
+		{lo + 5, 1, lo + 1<<48},
+		{2<<56 | lo, 1, 2<<56 | (lo + 1<<48)},
+	}
+
+	for _, b := range blocks {
+		if start := skipAmbiguous(b.start, b.n); start != b.expected {
+			t.Fatalf("invalid block start, expected %d and received %d", b.expected, start)
+		}
+	}
+
+	idg := New(lo - 1)
+	for i, expected := range []uint64{lo - 1, lo + 1<<48, lo + 1<<48 + 1} {
+		id := idg.Next()
+		if l := id.Layout(); l != LayoutIndexFirst {
+			t.Fatalf("invalid layout of ID #%d, expected %v and received %v", i, LayoutIndexFirst, l)
+		}
+
+		if err := testIndex(id, expected); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// The UUIDv7 layout has no ambiguous indexes
+	idg = New(lo, WithLayout(LayoutUUIDv7))
+	if err := testIndex(idg.Next(), lo); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParseUUID(t *testing.T) {
+	var (
+		nid ID
+		err error
+	)
+
+	id := newUUIDv7(1337, 1700000000000)
+	str := id.UUIDString()
+	if expected := "018bcfe5-6800-7000-8000-000000000539"; str != expected {
+		t.Fatalf("invalid UUID string, expected %s and received %s", expected, str)
+	}
+
+	if nid, err = ParseUUID(str); err != nil {
+		t.Fatal(err)
+	}
+
+	if id != nid {
+		t.Fatalf("ID's do not match: %v / %v", id.Bytes(), nid.Bytes())
+	}
+
+	if _, err = ParseUUID("018bcfe5-6800-7000-8000_000000000539"); err != ErrInvalidUUID {
+		t.Fatalf("invalid error, expected %v and received %v", ErrInvalidUUID, err)
+	}
+
+	// Index-first IDs should still be reported as such
+	legacy := newID(1337, -1)
+	if l := legacy.Layout(); l != LayoutIndexFirst {
+		t.Fatalf("invalid layout, expected %v and received %v", LayoutIndexFirst, l)
+	}
+}